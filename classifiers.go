@@ -0,0 +1,137 @@
+package jsonstruct
+
+import (
+	"encoding/base64"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// StringClassifier returns the StringFormat of input, or an empty string if it doesn't recognize it.
+type StringClassifier func(input string) StringFormat
+
+// DefaultClassifiers are the classifiers used when ParserOptions.InferFormats is set, in the order they are tried.
+//
+//nolint:gochecknoglobals
+var DefaultClassifiers = []StringClassifier{
+	UUIDClassifier,
+	IPClassifier,
+	EmailClassifier,
+	URIClassifier,
+	HostnameClassifier,
+	DurationClassifier,
+	Base64Classifier,
+}
+
+// minBase64Length is the length of the shortest string recognized as base64, since short words are valid base64 too.
+const minBase64Length = 16
+
+// UUIDClassifier recognizes UUIDs in their canonical form.
+func UUIDClassifier(input string) StringFormat {
+	if isUUID(input) {
+		return FormatUUID
+	}
+
+	return ""
+}
+
+// IPClassifier recognizes IPv4 and IPv6 addresses.
+func IPClassifier(input string) StringFormat {
+	addr, err := netip.ParseAddr(input)
+
+	switch {
+	case err != nil:
+		return ""
+	case addr.Is4():
+		return FormatIPv4
+	}
+
+	return FormatIPv6
+}
+
+// EmailClassifier recognizes plain email addresses with a fully qualified domain, e.g. "user@example.com". Addresses
+// with display names or quoted local parts aren't recognized.
+func EmailClassifier(input string) StringFormat {
+	local, domain, ok := strings.Cut(input, "@")
+	if !ok || local == "" || strings.ContainsAny(local, "@\"<>()[]\\,;:") ||
+		strings.IndexFunc(local, unicode.IsSpace) != -1 || HostnameClassifier(domain) == "" {
+		return ""
+	}
+
+	return FormatEmail
+}
+
+// URIClassifier recognizes absolute URLs with a scheme and a host, e.g. "https://example.com/a?b=c".
+func URIClassifier(input string) StringFormat {
+	if strings.IndexFunc(input, unicode.IsSpace) != -1 {
+		return ""
+	}
+
+	parsed, err := url.Parse(input)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return ""
+	}
+
+	return FormatURI
+}
+
+// HostnameClassifier recognizes fully qualified domain names, e.g. "api.example.com": dotted names ending with a label
+// made of letters. IP addresses aren't hostnames.
+func HostnameClassifier(input string) StringFormat {
+	lastLabel := input[strings.LastIndexByte(input, '.')+1:]
+	if len(input) > 253 || len(lastLabel) < 2 || !isHostname(input) ||
+		strings.IndexFunc(lastLabel, func(r rune) bool { return !unicode.IsLetter(r) }) != -1 {
+		return ""
+	}
+
+	return FormatHostname
+}
+
+// DurationClassifier recognizes Go durations with a unit, e.g. "1h30m" or "250ms".
+func DurationClassifier(input string) StringFormat {
+	if strings.IndexFunc(input, unicode.IsLetter) == -1 {
+		return ""
+	}
+
+	if _, err := time.ParseDuration(input); err != nil {
+		return ""
+	}
+
+	return FormatDuration
+}
+
+// Base64Classifier recognizes padded standard base64 of at least minBase64Length characters that mixes upper and lower
+// case letters with digits or symbols, so that words and hex strings aren't mistaken for it.
+func Base64Classifier(input string) StringFormat {
+	if len(input) < minBase64Length || len(input)%4 != 0 {
+		return ""
+	}
+
+	hasUpper := strings.IndexFunc(input, unicode.IsUpper) != -1
+	hasLower := strings.IndexFunc(input, unicode.IsLower) != -1
+	hasOther := strings.ContainsAny(input, "0123456789+/=")
+
+	if !hasUpper || !hasLower || !hasOther {
+		return ""
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(input); err != nil {
+		return ""
+	}
+
+	return FormatBase64
+}
+
+// classifyFormat returns the StringFormat found by the first of classifiers that recognizes input, or an empty string
+// if none of them do.
+func classifyFormat(input string, classifiers []StringClassifier) StringFormat {
+	for _, classifier := range classifiers {
+		if format := classifier(input); format != "" {
+			return format
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,234 @@
+package jsonstruct_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestClassifiers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected jsonstruct.StringFormat
+	}{
+		{"123e4567-e89b-12d3-a456-426614174000", jsonstruct.FormatUUID},
+		{"123e4567-e89b-12d3-a456-42661417400", ""},
+		{"10.0.0.1", jsonstruct.FormatIPv4},
+		{"2001:db8::1", jsonstruct.FormatIPv6},
+		{"10.0.0.256", ""},
+		{"user@example.com", jsonstruct.FormatEmail},
+		{"first.last+tag@mail.example.co", jsonstruct.FormatEmail},
+		{"Name <user@example.com>", ""},
+		{"user@localhost", ""},
+		{"https://example.com/a?b=c", jsonstruct.FormatURI},
+		{"postgres://user:pass@db:5432/app", jsonstruct.FormatURI},
+		{"/relative/path", ""},
+		{"mailto:user@example.com", ""},
+		{"api.example.com", jsonstruct.FormatHostname},
+		{"example.com", jsonstruct.FormatHostname},
+		{"v1.2", ""},
+		{"-bad.example.com", ""},
+		{"1h30m", jsonstruct.FormatDuration},
+		{"250ms", jsonstruct.FormatDuration},
+		{"0", ""},
+		{"SGVsbG8sIFdvcmxkIQ==", jsonstruct.FormatBase64},
+		{"AAECAwQFBgcICQoLDA0ODw==", jsonstruct.FormatBase64},
+		{"abcdefghijklmnop", ""},
+		{"0123456789abcdef0123456789abcdef", ""},
+		{"SGVsbG8", ""},
+		{"hello world", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.input, func(t *testing.T) {
+			t.Parallel()
+
+			format := jsonstruct.StringFormat("")
+
+			for _, classifier := range jsonstruct.DefaultClassifiers {
+				if format = classifier(test.input); format != "" {
+					break
+				}
+			}
+
+			assert.Equal(t, test.expected, format)
+		})
+	}
+}
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestParserInferFormats(t *testing.T) {
+	t.Parallel()
+
+	custom := func(input string) jsonstruct.StringFormat {
+		if strings.HasPrefix(input, "sku-") {
+			return "sku"
+		}
+
+		return ""
+	}
+
+	tests := []struct {
+		name       string
+		input      string
+		parserOpts *jsonstruct.ParserOptions
+		expected   string
+		formats    []jsonstruct.StringFormat
+	}{
+		{
+			name:       "disabled",
+			input:      `{"id": "123e4567-e89b-12d3-a456-426614174000", "ip": "10.0.0.1"}`,
+			parserOpts: &jsonstruct.ParserOptions{},
+			expected: "type Root struct {\n\tID string `json:\"id\" validate:\"required\"`\n" +
+				"\tIP string `json:\"ip\" validate:\"required\"`\n}",
+			formats: []jsonstruct.StringFormat{"", ""},
+		},
+		{
+			name: "types_and_rules",
+			input: `[{"id": "123e4567-e89b-12d3-a456-426614174000", "ip": "10.0.0.1", "key": "SGVsbG8sIFdvcmxkIQ==", ` +
+				`"email": "a@example.com", "hosts": ["a.example.com"], "name": "x"}, {"id": "123e4567-e89b-12d3-a456-` +
+				`426614174001", "ip": "::1", "key": "AAECAwQFBgcICQoLDA0ODw==", "name": "y", "hosts": null}]`,
+			parserOpts: &jsonstruct.ParserOptions{InferFormats: true},
+			expected: "type Root struct {\n\tID    string     `json:\"id\" validate:\"required,uuid\"`\n" +
+				"\tIP    netip.Addr `json:\"ip\" validate:\"required\"`\n" +
+				"\tKey   []byte     `json:\"key\" validate:\"required\"`\n" +
+				"\tEmail string     `json:\"email,omitempty\" validate:\"omitempty,email\"`\n" +
+				"\tHosts []string   `json:\"hosts\" validate:\"omitempty,dive,fqdn\"`\n" +
+				"\tName  string     `json:\"name\" validate:\"required\"`\n}",
+			formats: []jsonstruct.StringFormat{
+				jsonstruct.FormatUUID, jsonstruct.FormatIP, jsonstruct.FormatBase64, jsonstruct.FormatEmail, "", "",
+			},
+		},
+		{
+			name:       "mixed_formats",
+			input:      `[{"a": "10.0.0.1", "b": "10.0.0.1"}, {"a": "example.com", "b": null}]`,
+			parserOpts: &jsonstruct.ParserOptions{InferFormats: true},
+			expected: "type Root struct {\n\tA string      `json:\"a\" validate:\"required\"`\n" +
+				"\tB *netip.Addr `json:\"b\"`\n}",
+			formats: []jsonstruct.StringFormat{"", jsonstruct.FormatIPv4},
+		},
+		{
+			name:       "custom_classifier",
+			input:      `{"sku": "sku-1", "id": "123e4567-e89b-12d3-a456-426614174000"}`,
+			parserOpts: &jsonstruct.ParserOptions{Classifiers: []jsonstruct.StringClassifier{custom}},
+			expected: "type Root struct {\n\tSku string `json:\"sku\" validate:\"required\"`\n" +
+				"\tID  string `json:\"id\" validate:\"required\"`\n}",
+			formats: []jsonstruct.StringFormat{"sku", ""},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(test.input), slog.Default(), test.parserOpts)
+			assert.Nil(t, err)
+
+			structs, err := parser.Start()
+			assert.Nil(t, err)
+
+			formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{
+				Tags: []jsonstruct.TagOptions{{Key: jsonstruct.ValidateTag}},
+			})
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs[0].SetName("Root"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(output))
+
+			formats := []jsonstruct.StringFormat{}
+			for _, field := range structs[0].Fields() {
+				formats = append(formats, field.Format())
+			}
+
+			assert.Equal(t, test.formats, formats)
+		})
+	}
+}
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestFormatGoTypes(t *testing.T) {
+	t.Parallel()
+
+	input := `{"id": "123e4567-e89b-12d3-a456-426614174000", "ip": "10.0.0.1", "created": "2024-01-02T03:04:05Z"}`
+
+	tests := []struct {
+		name        string
+		formatTypes map[jsonstruct.StringFormat]string
+		expected    string
+		err         bool
+	}{
+		{
+			name: "defaults",
+			expected: "package test\n\nimport (\n\t\"net/netip\"\n\t\"time\"\n)\n\ntype Root struct {\n" +
+				"\tID      string     `json:\"id\"`\n\tIP      netip.Addr `json:\"ip\"`\n" +
+				"\tCreated time.Time  `json:\"created\"`\n}",
+		},
+		{
+			name: "import_path",
+			formatTypes: map[jsonstruct.StringFormat]string{
+				jsonstruct.FormatUUID:     "github.com/google/uuid.UUID",
+				jsonstruct.FormatIPv4:     "string",
+				jsonstruct.FormatDateTime: "*example.com/clock.Time",
+			},
+			expected: "package test\n\nimport (\n\t\"example.com/clock\"\n\t\"github.com/google/uuid\"\n)\n\n" +
+				"type Root struct {\n\tID      uuid.UUID   `json:\"id\"`\n\tIP      string      `json:\"ip\"`\n" +
+				"\tCreated *clock.Time `json:\"created\"`\n}",
+		},
+		{
+			name:        "missing_name",
+			formatTypes: map[jsonstruct.StringFormat]string{jsonstruct.FormatUUID: "example.com/testid"},
+			err:         true,
+		},
+		{
+			name:        "known_package_name",
+			formatTypes: map[jsonstruct.StringFormat]string{jsonstruct.FormatUUID: "example.com/time.Time"},
+			err:         true,
+		},
+		{
+			name:        "invalid_type",
+			formatTypes: map[jsonstruct.StringFormat]string{jsonstruct.FormatUUID: "not a type"},
+			err:         true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{
+				PackageName:   "test",
+				FormatGoTypes: test.formatTypes,
+			})
+			if test.err {
+				assert.NotNil(t, err)
+
+				return
+			}
+
+			assert.Nil(t, err)
+
+			parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(input), slog.Default(),
+				&jsonstruct.ParserOptions{InferFormats: true, InferTime: true})
+			assert.Nil(t, err)
+
+			structs, err := parser.Start()
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs[0].SetName("Root"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(output))
+		})
+	}
+}
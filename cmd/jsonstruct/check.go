@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/urfave/cli/v2"
+)
+
+var errOutOfDate = errors.New("generated code is out of date")
+
+// checkOutFile compares content with the file passed to --out-file, printing a unified diff and returning errOutOfDate
+// if they differ.
+func checkOutFile(ctx *cli.Context, content []byte) error {
+	path := ctx.String("out-file")
+	if path == "" {
+		return fmt.Errorf("--check needs an --out-file to compare the results with")
+	}
+
+	differs, err := diffFile(ctx.App.Writer, path, content)
+	if err != nil {
+		return err
+	}
+
+	if differs {
+		return fmt.Errorf("%w: %s", errOutOfDate, path)
+	}
+
+	return nil
+}
+
+// diffFile prints a unified diff between the file at path and content to out, and returns true if they differ. A
+// missing file is compared as an empty one.
+func diffFile(out io.Writer, path string, content []byte) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("failed to read existing file: %w", err)
+	}
+
+	if bytes.Equal(existing, content) {
+		log.Debug("file is up to date", "file", path)
+
+		return false, nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(string(existing)),
+		B:        diffLines(string(content)),
+		FromFile: path,
+		ToFile:   path + " (generated)",
+		Context:  3,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to diff %q: %w", path, err)
+	}
+
+	fmt.Fprint(out, diff)
+
+	return true, nil
+}
+
+// diffLines splits text into lines for difflib, keeping their line breaks.
+func diffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestDiffFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		existing string
+		missing  bool
+		content  string
+		differs  bool
+		diff     string
+	}{
+		{
+			name:     "up_to_date",
+			existing: "package models\n\ntype User struct{}\n",
+			content:  "package models\n\ntype User struct{}\n",
+		},
+		{
+			name:     "stale",
+			existing: "package models\n\ntype User struct {\n\tID int64 `json:\"id\"`\n}\n",
+			content:  "package models\n\ntype User struct {\n\tID   int64  `json:\"id\"`\n\tName string `json:\"name\"`\n}\n",
+			differs:  true,
+			diff: "--- PATH\n+++ PATH (generated)\n@@ -1,5 +1,6 @@\n package models\n \n type User struct {\n" +
+				"-\tID int64 `json:\"id\"`\n+\tID   int64  `json:\"id\"`\n+\tName string `json:\"name\"`\n }\n",
+		},
+		{
+			name:    "missing",
+			missing: true,
+			content: "package models\n",
+			differs: true,
+			diff:    "--- PATH\n+++ PATH (generated)\n@@ -0,0 +1 @@\n+package models\n",
+		},
+		{
+			name:     "empty",
+			existing: "",
+			content:  "",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "models.go")
+
+			if !test.missing {
+				writeTestFile(t, dir, "models.go", test.existing)
+			}
+
+			var out bytes.Buffer
+
+			differs, err := diffFile(&out, path, []byte(test.content))
+			assert.Nil(t, err)
+			assert.Equal(t, test.differs, differs)
+			assert.Equal(t, strings.ReplaceAll(test.diff, "PATH", path), out.String())
+		})
+	}
+}
+
+func TestCheckOutFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		existing string
+		outFile  bool
+		err      error
+		diff     bool
+	}{
+		{name: "up_to_date", existing: "package models\n", outFile: true},
+		{name: "stale", existing: "package old\n", outFile: true, err: errOutOfDate, diff: true},
+		{name: "missing", outFile: true, err: errOutOfDate, diff: true},
+		{name: "no_out_file", err: errors.New("--check needs an --out-file to compare the results with")},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "models.go")
+
+			if test.existing != "" {
+				writeTestFile(t, dir, "models.go", test.existing)
+			}
+
+			args := []string{"--check"}
+			if test.outFile {
+				args = append(args, "--out-file", path)
+			}
+
+			var out bytes.Buffer
+
+			ctx := newTestContext(t, args...)
+			ctx.App.Writer = &out
+
+			err := checkOutFile(ctx, []byte("package models\n"))
+
+			switch {
+			case test.err == nil:
+				assert.Nil(t, err)
+			case errors.Is(test.err, errOutOfDate):
+				assert.ErrorIs(t, err, errOutOfDate)
+				assert.ErrorContains(t, err, path)
+			default:
+				assert.EqualError(t, err, test.err.Error())
+			}
+
+			assert.Equal(t, test.diff, out.Len() > 0)
+		})
+	}
+}
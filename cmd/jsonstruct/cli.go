@@ -22,3 +22,60 @@ func httpCommand() *cli.Command {
 		},
 	}
 }
+
+func openAPICommand() *cli.Command {
+	return &cli.Command{
+		Name:      "openapi",
+		Action:    genOpenAPI,
+		ArgsUsage: "[FILE]",
+		Usage:     "generate Go types for the request and response bodies of an OpenAPI 3.x document",
+		Description: "This reads an OpenAPI 3.x document in JSON or YAML, from FILE or STDIN, and generates a type for " +
+			"the JSON body of every request and response, named after the operationId and status code. Bodies are " +
+			"typed from their examples when they have any, and from their schemas otherwise. The global flags (e.g. " +
+			"--package, --out-file) go before \"openapi\".",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "prefer-schemas",
+				Usage: "use the schemas of bodies rather than their examples when they have both",
+			},
+		},
+	}
+}
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "manage the project config file",
+		Description: "The options of jsonstruct can be kept in a " + configYAML + " or " + configJSON + " file, found " +
+			"by looking in the current directory and its parents. It holds the global flags, named without dashes, " +
+			"e.g. \"sort-fields: true\"; flags passed on the command line take precedence.",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "init",
+				Action:    configInit,
+				ArgsUsage: "[FILE]",
+				Usage:     "write a config file with every option commented out, to " + configYAML + " by default",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "overwrite FILE if it already exists",
+					},
+				},
+			},
+		},
+	}
+}
+
+func generateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "generate",
+		Action:    genManifest,
+		ArgsUsage: "[MANIFEST]",
+		Usage:     "write the files listed in a manifest, " + manifestYAML + " by default",
+		Description: "This reads a manifest in YAML or JSON listing files to write, with the inputs, type names and " +
+			"options of each, and only rewrites the files whose content changed, e.g. for a \"//go:generate " +
+			"jsonstruct generate\" line. Paths are relative to the manifest, options are keyed like the config file, " +
+			"and the package defaults to the one go generate runs in. The global flags go before \"generate\" and " +
+			"take precedence over the options of the manifest, which take precedence over the config file.",
+	}
+}
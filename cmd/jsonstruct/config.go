@@ -0,0 +1,292 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cneill/jsonstruct"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configYAML = ".jsonstruct.yaml"
+	configJSON = ".jsonstruct.json"
+	// configOverridesKey is the key of the App metadata holding the overrides listed in the config file
+	configOverridesKey = "overrides"
+	// commentWidth is the width of the comments in the config file written by "config init"
+	commentWidth = 118
+)
+
+// isConfigFlag returns true if the global flag called name can be set in the config file.
+func isConfigFlag(name string) bool {
+	switch name {
+	case "help", "debug", "config", "no-config", "check":
+		return false
+	}
+
+	return true
+}
+
+// isPathFlag returns true for the global flags that take a path, which is relative to the config file in it.
+func isPathFlag(name string) bool {
+	return name == "out-file" || name == "overrides"
+}
+
+// findConfig returns the path of the closest config file in dir or its parents, or an empty string if there isn't one.
+func findConfig(dir string) (string, error) {
+	for {
+		for _, name := range []string{configYAML, configJSON} {
+			path := filepath.Join(dir, name)
+
+			_, err := os.Stat(path)
+			if err == nil {
+				return path, nil
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return "", fmt.Errorf("failed to check for config file %q: %w", path, err)
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+
+		dir = parent
+	}
+}
+
+// applyConfig sets the global flags that weren't passed on the command line from the config file passed to --config,
+// or the closest one to the current directory unless --no-config was used.
+func applyConfig(ctx *cli.Context) error {
+	path := ctx.String("config")
+
+	if path == "" {
+		if ctx.Bool("no-config") {
+			return nil
+		}
+
+		dir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+
+		if path, err = findConfig(dir); err != nil || path == "" {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// JSON is YAML too
+	config := map[string]any{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+
+	log.Debug("loaded config file", "file", path)
+
+	return applyOptions(ctx, config, filepath.Dir(path), fmt.Sprintf("config file %q", path))
+}
+
+// applyOptions sets the global flags that weren't set yet from options, which are keyed like the config file and read
+// from source in dir.
+func applyOptions(ctx *cli.Context, options map[string]any, dir, source string) error {
+	flags := map[string]cli.Flag{}
+
+	for _, flag := range ctx.App.Flags {
+		if name := flag.Names()[0]; isConfigFlag(name) {
+			flags[name] = flag
+		}
+	}
+
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		flag, ok := flags[key]
+		if !ok {
+			return fmt.Errorf("unknown option %q in %s", key, source)
+		}
+
+		if isOptionSet(ctx, key) || options[key] == nil {
+			continue
+		}
+
+		if err := setConfigValue(ctx, flag, options[key], dir); err != nil {
+			return fmt.Errorf("invalid option %q in %s: %w", key, source, err)
+		}
+	}
+
+	return nil
+}
+
+// isOptionSet returns true if the global flag called name was already set, including overrides listed in a config file.
+func isOptionSet(ctx *cli.Context, name string) bool {
+	_, listed := ctx.App.Metadata[configOverridesKey]
+
+	return ctx.IsSet(name) || name == "overrides" && listed
+}
+
+// setConfigValue sets flag to value, read from a config file in dir. Lists set flags that can be repeated, and
+// "overrides" can hold the list of overrides itself rather than the path of a file.
+func setConfigValue(ctx *cli.Context, flag cli.Flag, value any, dir string) error {
+	name := flag.Names()[0]
+	list, isList := value.([]any)
+
+	switch {
+	case name == "overrides" && isList:
+		data, err := yaml.Marshal(list)
+		if err != nil {
+			return fmt.Errorf("failed to read overrides: %w", err)
+		}
+
+		overrides, err := jsonstruct.ParseOverrides(data)
+		if err != nil {
+			return err
+		}
+
+		ctx.App.Metadata[configOverridesKey] = overrides
+
+		return nil
+	case isList:
+		if _, ok := flag.(*cli.StringSliceFlag); !ok {
+			return fmt.Errorf("expecting a single value, got a list")
+		}
+
+		for _, item := range list {
+			if err := ctx.Set(name, fmt.Sprint(item)); err != nil {
+				return fmt.Errorf("failed to set value: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	if _, isMap := value.(map[string]any); isMap {
+		return fmt.Errorf("expecting a value, got an object")
+	}
+
+	str := fmt.Sprint(value)
+	if isPathFlag(name) && str != "" && !filepath.IsAbs(str) {
+		str = filepath.Join(dir, str)
+	}
+
+	if err := ctx.Set(name, str); err != nil {
+		return fmt.Errorf("failed to set value: %w", err)
+	}
+
+	return nil
+}
+
+// configInit writes a config file with every option commented out, set to its default value.
+func configInit(ctx *cli.Context) error {
+	path := configYAML
+	if ctx.NArg() > 0 {
+		path = ctx.Args().First()
+	}
+
+	// don't overwrite an existing file by mistake
+	mode := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if ctx.Bool("force") {
+		mode = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(path, mode, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("config file %q already exists, use --force to overwrite it", path)
+	} else if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+
+	defer file.Close()
+
+	if _, err := file.WriteString(defaultConfig(ctx.App.Flags)); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	fmt.Fprintf(ctx.App.Writer, "Wrote %s\n", path)
+
+	return nil
+}
+
+// defaultConfig returns the contents of a config file with each of the options of flags commented out, set to its
+// default value, below its usage.
+func defaultConfig(flags []cli.Flag) string {
+	var builder strings.Builder
+
+	builder.WriteString(commentLines("jsonstruct config, found by looking for " + configYAML + " or " + configJSON +
+		" in the current directory and its parents. It holds the global flags, named without dashes; flags passed on " +
+		"the command line take precedence. Paths are relative to this file. Uncomment the options you need."))
+
+	for _, flag := range flags {
+		docFlag, ok := flag.(cli.DocGenerationFlag)
+		name := flag.Names()[0]
+
+		if !ok || !isConfigFlag(name) {
+			continue
+		}
+
+		// e.g. "`FILE`" marks the placeholder in the usage of the flag
+		builder.WriteString("\n" + commentLines(strings.ReplaceAll(docFlag.GetUsage(), "`", "")))
+
+		var value any
+
+		switch typed := flag.(type) {
+		case *cli.StringFlag:
+			value = typed.Value
+		case *cli.BoolFlag:
+			value = typed.Value
+		case *cli.IntFlag:
+			value = typed.Value
+		case *cli.StringSliceFlag:
+			value = []string{}
+		}
+
+		// the overrides can be listed here rather than in their own file
+		if name == "overrides" {
+			builder.WriteString("# overrides:\n#   - path: $.orders[*].price\n" +
+				"#     type: github.com/shopspring/decimal.Decimal\n")
+
+			continue
+		}
+
+		line, _ := yaml.Marshal(map[string]any{name: value})
+		builder.WriteString("# " + string(line))
+	}
+
+	return builder.String()
+}
+
+// commentLines returns text as YAML comments wrapped at commentWidth.
+func commentLines(text string) string {
+	var builder strings.Builder
+
+	line := "#"
+
+	for _, word := range strings.Fields(text) {
+		if len(line)+1+len(word) > commentWidth {
+			builder.WriteString(line + "\n")
+
+			line = "#"
+		}
+
+		line += " " + word
+	}
+
+	builder.WriteString(line + "\n")
+
+	return builder.String()
+}
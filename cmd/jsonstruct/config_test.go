@@ -0,0 +1,353 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+
+	"github.com/cneill/jsonstruct"
+)
+
+// getAppFlag returns the global flag called name.
+func getAppFlag(t *testing.T, ctx *cli.Context, name string) cli.Flag {
+	t.Helper()
+
+	for _, appFlag := range ctx.App.Flags {
+		if appFlag.Names()[0] == name {
+			return appFlag
+		}
+	}
+
+	t.Fatalf("unknown flag %q", name)
+
+	return nil
+}
+
+// assertFlagValue checks the value of the flag called name in ctx, read according to the type of expected.
+func assertFlagValue(t *testing.T, ctx *cli.Context, name string, expected any) {
+	t.Helper()
+
+	switch expected := expected.(type) {
+	case string:
+		assert.Equal(t, expected, ctx.String(name), "flag %q", name)
+	case bool:
+		assert.Equal(t, expected, ctx.Bool(name), "flag %q", name)
+	case int:
+		assert.Equal(t, expected, ctx.Int(name), "flag %q", name)
+	case []string:
+		assert.Equal(t, expected, ctx.StringSlice(name), "flag %q", name)
+	default:
+		t.Fatalf("unexpected value %v for flag %q", expected, name)
+	}
+}
+
+//nolint:paralleltest,funlen // changes the current directory to find the config file in its parent
+func TestApplyConfig(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, configYAML, "package: models\nout-file: gen/models.go\nsort-fields: true\ntag: [db, bson]\n")
+	other := writeTestFile(t, root, "other/config.json", `{"package": "other", "overrides": "overrides.yaml"}`)
+	writeTestFile(t, root, "bad/config.yaml", "packages: models\n")
+	cwd := filepath.Join(root, "a", "b")
+
+	assert.Nil(t, os.MkdirAll(cwd, 0o755))
+
+	previous, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(cwd))
+
+	t.Cleanup(func() {
+		assert.Nil(t, os.Chdir(previous))
+	})
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected map[string]any
+		err      bool
+	}{
+		{
+			name: "parent",
+			args: []string{},
+			expected: map[string]any{
+				"package":     "models",
+				"out-file":    filepath.Join(root, "gen", "models.go"),
+				"sort-fields": true,
+				"tag":         []string{"db", "bson"},
+			},
+		},
+		{
+			name: "command_line",
+			args: []string{"--package", "cli", "--tag", "yaml", "-o", "cli.go"},
+			expected: map[string]any{
+				"package":     "cli",
+				"out-file":    "cli.go",
+				"sort-fields": true,
+				"tag":         []string{"yaml"},
+			},
+		},
+		{
+			name: "config_flag",
+			args: []string{"--config", other},
+			expected: map[string]any{
+				"package":     "other",
+				"overrides":   filepath.Join(root, "other", "overrides.yaml"),
+				"sort-fields": false,
+			},
+		},
+		{
+			name:     "no_config",
+			args:     []string{"--no-config"},
+			expected: map[string]any{"package": "", "out-file": "", "sort-fields": false},
+		},
+		{
+			name: "unknown_option",
+			args: []string{"--config", filepath.Join(root, "bad", "config.yaml")},
+			err:  true,
+		},
+		{
+			name: "missing_file",
+			args: []string{"--config", filepath.Join(root, "missing.yaml")},
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctx := newTestContext(t, test.args...)
+
+			err := applyConfig(ctx)
+			if test.err {
+				assert.NotNil(t, err)
+
+				return
+			}
+
+			assert.Nil(t, err)
+
+			for name, expected := range test.expected {
+				assertFlagValue(t, ctx, name, expected)
+			}
+		})
+	}
+}
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestApplyOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		// file, manifest, and config are applied in that order, like for the files of a manifest
+		file     map[string]any
+		manifest map[string]any
+		config   map[string]any
+		expected map[string]any
+		err      bool
+	}{
+		{
+			name:     "config",
+			config:   map[string]any{"package": "config", "enums": true},
+			expected: map[string]any{"package": "config", "enums": true},
+		},
+		{
+			name:     "manifest_over_config",
+			manifest: map[string]any{"package": "manifest"},
+			config:   map[string]any{"package": "config", "enums": true},
+			expected: map[string]any{"package": "manifest", "enums": true},
+		},
+		{
+			name:     "file_over_manifest",
+			file:     map[string]any{"package": "file"},
+			manifest: map[string]any{"package": "manifest", "enum-max-values": 3},
+			config:   map[string]any{"package": "config", "enum-max-values": 5},
+			expected: map[string]any{"package": "file", "enum-max-values": 3},
+		},
+		{
+			name:     "command_line_over_file",
+			args:     []string{"--package", "cli", "--tag", "yaml"},
+			file:     map[string]any{"package": "file", "tag": []any{"db"}},
+			manifest: map[string]any{"tag": []any{"bson"}},
+			expected: map[string]any{"package": "cli", "tag": []string{"yaml"}},
+		},
+		{
+			name:     "lists_are_not_merged",
+			manifest: map[string]any{"tag": []any{"db", "bson"}},
+			config:   map[string]any{"tag": []any{"yaml"}},
+			expected: map[string]any{"tag": []string{"db", "bson"}},
+		},
+		{
+			name:     "relative_paths",
+			file:     map[string]any{"overrides": "overrides.yaml"},
+			config:   map[string]any{"out-file": "/tmp/models.go", "name": "models/v2"},
+			expected: map[string]any{"out-file": "/tmp/models.go", "name": "models/v2"},
+		},
+		{
+			name:     "null",
+			file:     map[string]any{"package": nil},
+			config:   map[string]any{"package": "config"},
+			expected: map[string]any{"package": "config"},
+		},
+		{
+			name:   "unknown_option",
+			config: map[string]any{"packages": "models"},
+			err:    true,
+		},
+		{
+			name: "command_line_only",
+			file: map[string]any{"check": true},
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			ctx := newTestContext(t, test.args...)
+
+			var err error
+
+			for _, options := range []map[string]any{test.file, test.manifest, test.config} {
+				if err = applyOptions(ctx, options, dir, "test"); err != nil {
+					break
+				}
+			}
+
+			if test.err {
+				assert.NotNil(t, err)
+
+				return
+			}
+
+			assert.Nil(t, err)
+
+			for name, expected := range test.expected {
+				assertFlagValue(t, ctx, name, expected)
+			}
+
+			if _, ok := test.file["overrides"]; ok {
+				assert.Equal(t, filepath.Join(dir, "overrides.yaml"), ctx.String("overrides"))
+			}
+		})
+	}
+}
+
+func TestIsOptionSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		listed   bool
+		option   string
+		expected bool
+	}{
+		{"unset", []string{}, false, "package", false},
+		{"command_line", []string{"-p", "models"}, false, "package", true},
+		{"alias", []string{"-p", "models"}, false, "p", true},
+		{"listed_overrides", []string{}, true, "overrides", true},
+		{"listed_other", []string{}, true, "package", false},
+		{"overrides_file", []string{"--overrides", "overrides.yaml"}, false, "overrides", true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := newTestContext(t, test.args...)
+			if test.listed {
+				ctx.App.Metadata[configOverridesKey] = []jsonstruct.Override{}
+			}
+
+			assert.Equal(t, test.expected, isOptionSet(ctx, test.option))
+		})
+	}
+}
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestSetConfigValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		flag      string
+		value     any
+		expected  any
+		relative  bool
+		overrides []jsonstruct.Override
+		err       bool
+	}{
+		{name: "string", flag: "package", value: "models", expected: "models"},
+		{name: "bool", flag: "enums", value: true, expected: true},
+		{name: "int", flag: "enum-max-values", value: 3, expected: 3},
+		{name: "list", flag: "tag", value: []any{"db", "bson:snake"}, expected: []string{"db", "bson:snake"}},
+		{name: "list_not_repeatable", flag: "package", value: []any{"a", "b"}, err: true},
+		{name: "object", flag: "package", value: map[string]any{"a": "b"}, err: true},
+		{name: "invalid_bool", flag: "enums", value: "maybe", err: true},
+		{name: "relative_path", flag: "out-file", value: "gen/models.go", expected: "gen/models.go", relative: true},
+		{name: "absolute_path", flag: "out-file", value: "/tmp/models.go", expected: "/tmp/models.go"},
+		{name: "overrides_file", flag: "overrides", value: "overrides.yaml", expected: "overrides.yaml", relative: true},
+		{
+			name: "inline_overrides",
+			flag: "overrides",
+			value: []any{
+				map[string]any{"path": "$.orders[*].price", "type": "github.com/shopspring/decimal.Decimal"},
+				map[string]any{"key": "internal", "skip": true},
+			},
+			expected: "",
+			overrides: []jsonstruct.Override{
+				{Path: "$.orders[*].price", Type: "github.com/shopspring/decimal.Decimal"},
+				{Key: "internal", Skip: true},
+			},
+		},
+		{
+			name:  "invalid_inline_overrides",
+			flag:  "overrides",
+			value: []any{map[string]any{"type": "string"}},
+			err:   true,
+		},
+		{
+			name:  "unknown_override_key",
+			flag:  "overrides",
+			value: []any{map[string]any{"key": "id", "kind": "string"}},
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			ctx := newTestContext(t)
+
+			err := setConfigValue(ctx, getAppFlag(t, ctx, test.flag), test.value, dir)
+			if test.err {
+				assert.NotNil(t, err)
+
+				return
+			}
+
+			assert.Nil(t, err)
+
+			expected := test.expected
+			if test.relative {
+				expected = filepath.Join(dir, test.expected.(string))
+			}
+
+			assertFlagValue(t, ctx, test.flag, expected)
+
+			overrides, _ := ctx.App.Metadata[configOverridesKey].([]jsonstruct.Override)
+			assert.Equal(t, test.overrides, overrides)
+		})
+	}
+}
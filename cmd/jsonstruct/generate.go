@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cneill/jsonstruct"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	manifestYAML = "jsonstruct.manifest.yaml"
+	manifestJSON = "jsonstruct.manifest.json"
+)
+
+// manifest lists the files written by the generate command.
+type manifest struct {
+	// Options are used by every file, keyed like the config file
+	Options map[string]any  `yaml:"options"`
+	Files   []*manifestFile `yaml:"files"`
+}
+
+// manifestFile is a file written by the generate command, with the types of its inputs.
+type manifestFile struct {
+	// Output is the path of the file, relative to the manifest
+	Output string `yaml:"output"`
+	// Inputs are the paths of the samples or glob patterns matching them, relative to the manifest
+	Inputs []string `yaml:"inputs"`
+	// Name is the name of the types, like --name
+	Name string `yaml:"name"`
+	// Options take precedence over those of the manifest
+	Options map[string]any `yaml:"options"`
+}
+
+// OK ensures that the entry is valid.
+func (m *manifestFile) OK() error {
+	if m.Output == "" {
+		return fmt.Errorf("missing output")
+	}
+
+	if len(m.Inputs) == 0 {
+		return fmt.Errorf("missing inputs for %q", m.Output)
+	}
+
+	if _, ok := m.Options["out-file"]; ok {
+		return fmt.Errorf("out-file can't be used in the options of %q, use output", m.Output)
+	}
+
+	return nil
+}
+
+// findManifest returns the path of the manifest in the current directory.
+func findManifest() (string, error) {
+	for _, path := range []string{manifestYAML, manifestJSON} {
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("failed to check for manifest %q: %w", path, err)
+		}
+	}
+
+	return "", fmt.Errorf("no %s or %s in the current directory", manifestYAML, manifestJSON)
+}
+
+// readManifest reads the manifest at path. Unknown keys are rejected.
+func readManifest(path string) (*manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	// JSON is YAML too
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	result := &manifest{}
+	if err := decoder.Decode(result); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %q: %w", path, err)
+	}
+
+	if _, ok := result.Options["out-file"]; ok {
+		return nil, fmt.Errorf("out-file can't be used in the options of manifest %q, use output", path)
+	}
+
+	if len(result.Files) == 0 {
+		return nil, fmt.Errorf("no files in manifest %q", path)
+	}
+
+	for i, file := range result.Files {
+		if err := file.OK(); err != nil {
+			return nil, fmt.Errorf("invalid file %d in manifest %q: %w", i, path, err)
+		}
+	}
+
+	return result, nil
+}
+
+// genManifest writes every file listed in the manifest passed as argument, or the one in the current directory, and
+// leaves those whose content didn't change untouched. In check mode, it lists the files that are out of date instead.
+func genManifest(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		return fmt.Errorf("expecting a single manifest, got %d", ctx.NArg())
+	}
+
+	path := ctx.Args().First()
+	if path == "" {
+		var err error
+		if path, err = findManifest(); err != nil {
+			return err
+		}
+	}
+
+	contents, err := readManifest(path)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	outdated := []string{}
+	// the types declared by the files written so far, by directory, which the next files in the same package can't use
+	declared := map[string][]string{}
+
+	for i, file := range contents.Files {
+		source := fmt.Sprintf("file %d of manifest %q", i, path)
+
+		// overrides listed in the config file or the manifest are kept in the metadata of the App
+		delete(ctx.App.Metadata, configOverridesKey)
+
+		fileCtx, err := manifestFileContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		if file.Name != "" {
+			if err := fileCtx.Set("name", file.Name); err != nil {
+				return fmt.Errorf("failed to set name of %s: %w", source, err)
+			}
+		}
+
+		if err := applyOptions(fileCtx, file.Options, dir, source); err != nil {
+			return err
+		}
+
+		if err := applyOptions(fileCtx, contents.Options, dir, fmt.Sprintf("manifest %q", path)); err != nil {
+			return err
+		}
+
+		if err := applyConfig(fileCtx); err != nil {
+			return err
+		}
+
+		err = genManifestFile(fileCtx, file, dir, declared)
+		if errors.Is(err, errOutOfDate) {
+			outdated = append(outdated, file.Output)
+		} else if err != nil {
+			return fmt.Errorf("failed to generate %q: %w", file.Output, err)
+		}
+	}
+
+	if len(outdated) > 0 {
+		return fmt.Errorf("%w: %s", errOutOfDate, strings.Join(outdated, ", "))
+	}
+
+	return nil
+}
+
+// manifestFileContext returns a fresh context for the global flags, holding those that were passed on the command line
+// so that they take precedence over the options of the manifest.
+func manifestFileContext(ctx *cli.Context) (*cli.Context, error) {
+	set := flag.NewFlagSet(ctx.App.Name, flag.ContinueOnError)
+
+	for _, appFlag := range ctx.App.Flags {
+		if err := appFlag.Apply(set); err != nil {
+			return nil, fmt.Errorf("failed to set up flags: %w", err)
+		}
+	}
+
+	fileCtx := cli.NewContext(ctx.App, set, nil)
+
+	for _, appFlag := range ctx.App.Flags {
+		name := appFlag.Names()[0]
+		if !ctx.IsSet(name) {
+			continue
+		}
+
+		values := []string{fmt.Sprint(ctx.Value(name))}
+		if _, ok := appFlag.(*cli.StringSliceFlag); ok {
+			values = ctx.StringSlice(name)
+		}
+
+		for _, value := range values {
+			if err := fileCtx.Set(name, value); err != nil {
+				return nil, fmt.Errorf("failed to set %q: %w", name, err)
+			}
+		}
+	}
+
+	return fileCtx, nil
+}
+
+// genManifestFile renders the inputs of file, and writes the result to its output unless it is already up to date, or
+// only compares them in check mode. The package defaults to the one go generate runs in. Types are named around those
+// in declared for the directory of the output, to which the types of the file are then added.
+func genManifestFile(ctx *cli.Context, file *manifestFile, dir string, declared map[string][]string) error {
+	formatterOpts, err := getFormatterOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	outPath := filepath.Join(dir, file.Output)
+	outDir := filepath.Dir(outPath)
+	formatterOpts.ReservedNames = declared[outDir]
+
+	outputFormat := ctx.String("output-format")
+
+	if outputFormat == outputGo && formatterOpts.PackageName == "" {
+		formatterOpts.PackageName = os.Getenv("GOPACKAGE")
+		if formatterOpts.PackageName == "" {
+			return fmt.Errorf("missing package, set it in the options or run through go generate")
+		}
+	}
+
+	formatter, err := newFormatter(outputFormat, formatterOpts)
+	if err != nil {
+		return fmt.Errorf("failed to set up formatter: %w", err)
+	}
+
+	namer, err := jsonstruct.NewTypeNamer(ctx.String("name"))
+	if err != nil {
+		return fmt.Errorf("failed to set up names: %w", err)
+	}
+
+	inputOpts := &inputOptions{
+		parserOpts: getParserOptions(ctx),
+		namer:      namer,
+		schema:     ctx.Bool("schema"),
+	}
+
+	inputs, err := openManifestInputs(file.Inputs, dir)
+	if err != nil {
+		return err
+	}
+
+	defer closeInputs(inputs)
+
+	var result bytes.Buffer
+	if err := genFile(formatter, inputOpts, inputs, &result); err != nil {
+		return err
+	}
+
+	if outputFormat == outputGo {
+		names, err := declaredNames(result.Bytes())
+		if err != nil {
+			return err
+		}
+
+		declared[outDir] = append(declared[outDir], names...)
+	}
+
+	if ctx.Bool("check") {
+		differs, err := diffFile(ctx.App.Writer, outPath, result.Bytes())
+		if err != nil {
+			return err
+		}
+
+		if differs {
+			return errOutOfDate
+		}
+
+		return nil
+	}
+
+	return writeIfChanged(ctx.App.Writer, outPath, result.Bytes())
+}
+
+// declaredNames returns the names of the types, constants, variables, and functions declared in the Go source src.
+func declaredNames(src []byte) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse generated source: %w", err)
+	}
+
+	names := []string{}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			// methods are scoped to their type
+			if decl.Recv == nil {
+				names = append(names, decl.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, spec.Name.Name)
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						names = append(names, name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// openManifestInputs opens the files matching patterns, which are relative to dir. Each pattern has to match a file.
+func openManifestInputs(patterns []string, dir string) ([]*os.File, error) {
+	inputs := []*os.File{}
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			closeInputs(inputs)
+
+			return nil, fmt.Errorf("invalid input pattern %q: %w", pattern, err)
+		}
+
+		if len(matches) == 0 {
+			closeInputs(inputs)
+
+			return nil, fmt.Errorf("no inputs matching %q", pattern)
+		}
+
+		for _, match := range matches {
+			file, err := os.Open(match)
+			if err != nil {
+				closeInputs(inputs)
+
+				return nil, fmt.Errorf("failed to open file %q: %w", match, err)
+			}
+
+			log.Debug("opened file to read JSON structs", "file", match)
+
+			inputs = append(inputs, file)
+		}
+	}
+
+	return inputs, nil
+}
+
+// closeInputs closes the files in inputs, some of which may have been closed already by parseInput.
+func closeInputs(inputs []*os.File) {
+	for _, input := range inputs {
+		input.Close()
+	}
+}
+
+// writeIfChanged writes content to the file at path, creating its directory, unless it already holds content. Files
+// that are written are reported to out.
+func writeIfChanged(out io.Writer, path string, content []byte) error {
+	existing, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(existing, content) {
+		log.Debug("file is up to date", "file", path)
+
+		return nil
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read existing file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, content, 0o644); err != nil { //nolint:gosec // generated source code
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	fmt.Fprintf(out, "Wrote %s\n", path)
+
+	return nil
+}
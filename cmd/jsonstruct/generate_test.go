@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestReadManifest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		fileName string
+		input    string
+		files    int
+		err      bool
+	}{
+		{
+			name:     "yaml",
+			fileName: manifestYAML,
+			input: "options:\n  package: models\nfiles:\n  - output: order.go\n    inputs: [order.json]\n" +
+				"    name: Order\n    options:\n      enums: true\n  - output: cart.go\n    inputs: [cart/*.json]\n",
+			files: 2,
+		},
+		{
+			name:     "json",
+			fileName: manifestJSON,
+			input:    `{"files": [{"output": "order.go", "inputs": ["order.json"]}]}`,
+			files:    1,
+		},
+		{
+			name:     "unknown_key",
+			fileName: manifestYAML,
+			input:    "package: models\nfiles:\n  - output: order.go\n    inputs: [order.json]\n",
+			err:      true,
+		},
+		{
+			name:     "unknown_file_key",
+			fileName: manifestYAML,
+			input:    "files:\n  - output: order.go\n    input: [order.json]\n",
+			err:      true,
+		},
+		{
+			name:     "out_file_in_options",
+			fileName: manifestYAML,
+			input:    "options:\n  out-file: models.go\nfiles:\n  - output: order.go\n    inputs: [order.json]\n",
+			err:      true,
+		},
+		{
+			name:     "out_file_in_file_options",
+			fileName: manifestYAML,
+			input:    "files:\n  - output: order.go\n    inputs: [order.json]\n    options:\n      out-file: x.go\n",
+			err:      true,
+		},
+		{
+			name:     "no_files",
+			fileName: manifestYAML,
+			input:    "options:\n  package: models\n",
+			err:      true,
+		},
+		{
+			name:     "missing_output",
+			fileName: manifestYAML,
+			input:    "files:\n  - inputs: [order.json]\n",
+			err:      true,
+		},
+		{
+			name:     "missing_inputs",
+			fileName: manifestYAML,
+			input:    "files:\n  - output: order.go\n",
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeTestFile(t, t.TempDir(), test.fileName, test.input)
+
+			result, err := readManifest(path)
+			if test.err {
+				assert.NotNil(t, err)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Len(t, result.Files, test.files)
+		})
+	}
+}
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestManifestFileContext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected map[string]any
+	}{
+		{
+			name:     "none",
+			args:     []string{},
+			expected: map[string]any{},
+		},
+		{
+			name:     "string",
+			args:     []string{"--package", "models", "-n", "Order"},
+			expected: map[string]any{"package": "models", "name": "Order"},
+		},
+		{
+			name:     "bool",
+			args:     []string{"--enums", "--no-config"},
+			expected: map[string]any{"enums": true, "no-config": true},
+		},
+		{
+			name:     "int",
+			args:     []string{"--enum-max-values", "3"},
+			expected: map[string]any{"enum-max-values": 3},
+		},
+		{
+			name:     "string_slice",
+			args:     []string{"--tag", "db", "--tag", "bson:snake", "--format-type", "uuid=example.com/id.ID"},
+			expected: map[string]any{"tag": []string{"db", "bson:snake"}, "format-type": []string{"uuid=example.com/id.ID"}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := newTestContext(t, test.args...)
+
+			fileCtx, err := manifestFileContext(ctx)
+			assert.Nil(t, err)
+
+			for _, appFlag := range ctx.App.Flags {
+				name := appFlag.Names()[0]
+				_, expected := test.expected[name]
+				assert.Equal(t, expected, fileCtx.IsSet(name), "flag %q", name)
+			}
+
+			for name, value := range test.expected {
+				switch value := value.(type) {
+				case string:
+					assert.Equal(t, value, fileCtx.String(name))
+				case bool:
+					assert.Equal(t, value, fileCtx.Bool(name))
+				case int:
+					assert.Equal(t, value, fileCtx.Int(name))
+				case []string:
+					assert.Equal(t, value, fileCtx.StringSlice(name))
+				}
+			}
+
+			// the options of a file don't leak into the command line flags used by the next one
+			assert.Nil(t, fileCtx.Set("out-file", "order.go"))
+			assert.Nil(t, fileCtx.Set("tag", "yaml"))
+			assert.False(t, ctx.IsSet("out-file"))
+			assert.Equal(t, test.expected["tag"] != nil, ctx.IsSet("tag"))
+		})
+	}
+}
+
+func TestWriteIfChanged(t *testing.T) {
+	t.Parallel()
+
+	past := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		existing string
+		content  string
+		written  bool
+	}{
+		{"missing", "", "package models\n", true},
+		{"changed", "package models\n\ntype A struct{}\n", "package models\n", true},
+		{"unchanged", "package models\n", "package models\n", false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "models", "models.go")
+
+			if test.existing != "" {
+				writeTestFile(t, dir, filepath.Join("models", "models.go"), test.existing)
+				assert.Nil(t, os.Chtimes(path, past, past))
+			}
+
+			var out bytes.Buffer
+
+			assert.Nil(t, writeIfChanged(&out, path, []byte(test.content)))
+			assert.Equal(t, test.written, out.String() == "Wrote "+path+"\n")
+
+			result, err := os.ReadFile(path)
+			assert.Nil(t, err)
+			assert.Equal(t, test.content, string(result))
+
+			info, err := os.Stat(path)
+			assert.Nil(t, err)
+			assert.Equal(t, test.written, !info.ModTime().Equal(past))
+		})
+	}
+}
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestGenManifestCollisions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		manifest string
+		expected map[string][]string
+	}{
+		{
+			name: "same_package",
+			manifest: "options:\n  package: models\nfiles:\n" +
+				"  - {output: order.go, inputs: [order.json], name: Order}\n" +
+				"  - {output: cart.go, inputs: [cart.json], name: Cart}\n",
+			expected: map[string][]string{
+				"order.go": {"Order", "Items"},
+				"cart.go":  {"Cart", "CartItems"},
+			},
+		},
+		{
+			name: "number",
+			manifest: "options:\n  package: models\n  collision-naming: number\nfiles:\n" +
+				"  - {output: order.go, inputs: [order.json], name: Order}\n" +
+				"  - {output: cart.go, inputs: [cart.json], name: Cart}\n",
+			expected: map[string][]string{
+				"order.go": {"Order", "Items"},
+				"cart.go":  {"Cart", "Items2"},
+			},
+		},
+		{
+			name: "other_package",
+			manifest: "options:\n  package: models\nfiles:\n" +
+				"  - {output: order.go, inputs: [order.json], name: Order}\n" +
+				"  - {output: cart/cart.go, inputs: [cart.json], name: Cart, options: {package: cart}}\n",
+			expected: map[string][]string{
+				"order.go":     {"Order", "Items"},
+				"cart/cart.go": {"Cart", "Items"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			writeTestFile(t, dir, "order.json", `{"id": 1, "items": {"sku": "a", "qty": 1}}`)
+			writeTestFile(t, dir, "cart.json", `{"name": "x", "items": {"added": "y"}}`)
+			path := writeTestFile(t, dir, manifestYAML, test.manifest)
+
+			assert.Nil(t, newApp().Run([]string{"jsonstruct", "--no-config", "generate", path}))
+
+			for output, expected := range test.expected {
+				content, err := os.ReadFile(filepath.Join(dir, output))
+				assert.Nil(t, err)
+
+				names, err := declaredNames(content)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, names, output)
+			}
+		})
+	}
+}
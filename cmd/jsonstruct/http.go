@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"html/template"
 	"io/fs"
@@ -62,19 +63,45 @@ func GenerateHandler(writer http.ResponseWriter, req *http.Request) {
 
 	r := strings.NewReader(input)
 
-	parser := jsonstruct.NewParser(r, log)
+	parser, err := jsonstruct.NewParserWithOptions(r, log, &jsonstruct.ParserOptions{
+		InferTime:  req.PostForm.Get("infer_time") == "on",
+		DetectMaps: req.PostForm.Get("detect_maps") == "on",
+		Lenient:    req.PostForm.Get("lenient") == "on",
+	})
+	if err != nil {
+		doErr(writer, fmt.Errorf("failed to set up parser: %w", err))
+		return
+	}
+
+	namer, err := jsonstruct.NewTypeNamer(req.PostForm.Get("name"))
+	if err != nil {
+		doErr(writer, fmt.Errorf("failed to set up names: %w", err))
+		return
+	}
 
 	jStructs, err := parser.Start()
 	if err != nil {
+		// show problems with the input in place of the output, since they're likely to be fixed as the user types
+		parseErr := &jsonstruct.ParseError{}
+		if errors.As(err, &parseErr) {
+			renderGenerated(writer, generateTemplate, "", parseErr.Detail())
+			return
+		}
+
 		doErr(writer, fmt.Errorf("failed to parse input: %w", err))
+
 		return
 	}
 
-	// set the names of the top-level structs from our example file based on the file's name
-	name := "WebGenerated"
+	// without a name, the top-level structs are called WebGenerated1, WebGenerated2, etc.
+	if err := namer.NameStructs("web_generated", jStructs); err != nil {
+		doErr(writer, fmt.Errorf("failed to name structs: %w", err))
+		return
+	}
 
-	for i := 0; i < len(jStructs); i++ {
-		jStructs[i].SetName(fmt.Sprintf("%s%d", name, i+1))
+	if err := namer.Done(); err != nil {
+		doErr(writer, fmt.Errorf("failed to name structs: %w", err))
+		return
 	}
 
 	formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{
@@ -93,11 +120,15 @@ func GenerateHandler(writer http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// fmt.Fprintf(outFile, "%s\n", result)
+	renderGenerated(writer, generateTemplate, result, "")
+}
 
+// renderGenerated renders either the generated structs or the error found in the input.
+func renderGenerated(writer http.ResponseWriter, generateTemplate *template.Template, generated, errStr string) {
 	data := struct {
 		Generated string
-	}{result}
+		Error     string
+	}{generated, errStr}
 
 	if err := generateTemplate.Execute(writer, data); err != nil {
 		doErr(writer, fmt.Errorf("failed to execute generate template: %w", err))
@@ -15,21 +15,6 @@ window.onload = function() {
     });
 }
 
-document.body.addEventListener("htmx:beforeRequest", e => {
-    let inputElem = e.detail.elt.querySelector(".input");
-    if (!inputElem) {
-        return;
-    }
-
-    try {
-        JSON.parse(inputElem.value);
-    } catch (err) {
-        if (inputElem.value != "") {
-            e.preventDefault();
-        }
-    }
-});
-
 document.body.addEventListener("htmx:beforeSwap", e => {
     if (e.detail.xhr.status >= 400) {
         e.detail.shouldSwap = false;
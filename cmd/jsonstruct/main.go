@@ -1,9 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/cneill/jsonstruct"
@@ -18,7 +23,16 @@ var (
 )
 
 func run() error {
-	app := &cli.App{
+	if err := newApp().Run(os.Args); err != nil {
+		return fmt.Errorf("ERROR: %w", err)
+	}
+
+	return nil
+}
+
+// newApp returns the jsonstruct App, with its global flags and commands.
+func newApp() *cli.App {
+	return &cli.App{
 		Name:        "jsonstruct",
 		Action:      genStructs,
 		ArgsUsage:   "[FILE]...",
@@ -28,7 +42,8 @@ func run() error {
 			&cli.StringFlag{
 				Name:    "name",
 				Aliases: []string{"n"},
-				Usage:   "override the default name derived from filename",
+				Usage: "override the default name derived from filename with `NAME`: a single name, a comma-separated " +
+					"list, or a template like \"{{.Name}}{{.Index}}\"",
 			},
 			&cli.BoolFlag{
 				Name:    "value-comments",
@@ -45,16 +60,154 @@ func run() error {
 				Aliases: []string{"i"},
 				Usage:   "use inline structs instead of creating different types for each object",
 			},
+			&cli.BoolFlag{
+				Name:    "infer-time",
+				Aliases: []string{"t"},
+				Usage:   "use time.Time for string values that look like RFC 3339 timestamps, and a Date type for dates",
+			},
+			&cli.BoolFlag{
+				Name: "infer-numbers",
+				Usage: "use int64 / float64 with the \",string\" tag option for string fields that hold a number in " +
+					"every sample, e.g. \"1234567890123\"",
+			},
+			&cli.BoolFlag{
+				Name: "infer-formats",
+				Usage: "recognize UUIDs, URLs, IP addresses, emails, hostnames, durations, and base64 in string values, " +
+					"typing IP addresses as netip.Addr and base64 as []byte, and validating the others with --tag validate",
+			},
+			&cli.StringSliceFlag{
+				Name: "format-type",
+				Usage: "use `FORMAT=TYPE` for values of FORMAT (e.g. \"uuid\", \"ipv4\", \"uri\", \"byte\"), where TYPE " +
+					"can name its package by import path, e.g. \"uuid=github.com/google/uuid.UUID\"; can be repeated",
+			},
+			&cli.BoolFlag{
+				Name:    "detect-maps",
+				Aliases: []string{"m"},
+				Usage:   "use map[string]T for objects whose keys look like data (IDs, dates, UUIDs...) rather than field names",
+			},
+			&cli.StringSliceFlag{
+				Name:  "map-key",
+				Usage: "always use a map for the object under `KEY` (\"$\" for the top-level object); can be repeated",
+			},
+			&cli.BoolFlag{
+				Name:    "lenient",
+				Aliases: []string{"l"},
+				Usage: "accept JSONC / JSON5 input (comments, trailing commas, unquoted keys...), turning comments into " +
+					"field docs; always on for .jsonc and .json5 files",
+			},
+			&cli.BoolFlag{
+				Name:    "ndjson",
+				Aliases: []string{"jsonl"},
+				Usage: "treat every value in the input as a sample of the same type and generate a single struct for " +
+					"them; always on for .ndjson and .jsonl files",
+			},
+			&cli.StringFlag{
+				Name: "input-format",
+				Usage: "parse the input as `FORMAT`: \"json\", \"yaml\", or \"toml\"; by default, .yaml / .yml and .toml " +
+					"files are parsed as YAML and TOML, and anything else as JSON",
+			},
+			&cli.BoolFlag{
+				Name: "schema",
+				Usage: "treat the input as a JSON Schema (draft 2020-12) describing the values rather than a sample of " +
+					"them; always on for .schema.json files",
+			},
+			&cli.BoolFlag{
+				Name:    "dedupe-structs",
+				Aliases: []string{"D"},
+				Usage:   "declare a single shared type for nested objects with identical shapes",
+			},
+			&cli.BoolFlag{
+				Name:  "recursive",
+				Usage: "reuse the type of an enclosing object for nested objects with a subset of its fields, e.g. replies",
+			},
+			&cli.StringFlag{
+				Name:  "dedupe-naming",
+				Usage: "choose the name of shared types by `POLICY`: \"first\" (first seen) or \"shortest\"",
+				Value: string(jsonstruct.NamingFirstSeen),
+			},
+			&cli.StringSliceFlag{
+				Name:  "type-name",
+				Usage: "name shared types that would have been called OLD NEW instead (`OLD=NEW`); can be repeated",
+			},
+			&cli.StringFlag{
+				Name:  "collision-naming",
+				Usage: "rename different nested types with the same name by `POLICY`: \"parent\" or \"number\"",
+				Value: string(jsonstruct.CollisionParent),
+			},
+			&cli.StringSliceFlag{
+				Name: "tag",
+				Usage: "add a struct tag to every field (`KEY[:NAMING][:omitempty]`), with NAMING \"original\", \"snake\", " +
+					"or \"camel\", e.g. \"bson:snake:omitempty\"; \"validate\" marks required fields; can be repeated",
+			},
+			&cli.BoolFlag{
+				Name:  "always-tag",
+				Usage: "add the json (or yaml / toml) tag even to fields named like their keys",
+			},
+			&cli.BoolFlag{
+				Name:    "enums",
+				Aliases: []string{"e"},
+				Usage: "declare a string type with a constant for each value for string fields that take a small set " +
+					"of values across samples (e.g. \"status\")",
+			},
+			&cli.IntFlag{
+				Name:  "enum-max-values",
+				Usage: "declare enums for fields with at most `N` distinct values (requires --enums)",
+				Value: jsonstruct.DefaultEnumMaxValues,
+			},
+			&cli.BoolFlag{
+				Name:  "enum-methods",
+				Usage: "add a Valid method and an UnmarshalJSON method rejecting unknown values to enums (requires --enums)",
+			},
+			&cli.StringFlag{
+				Name: "overrides",
+				Usage: "apply the overrides in the YAML / JSON `FILE`, a list of rules selecting fields by \"path\" " +
+					"(e.g. \"$.orders[*].price\") or \"key\" and setting their \"type\", \"name\", \"tag\", " +
+					"\"omitempty\", \"required\", or \"skip\"",
+			},
+			&cli.StringFlag{
+				Name: "null-type",
+				Usage: "use `TYPE` for fields that are null in every sample, where TYPE can name its package by import path, " +
+					"e.g. \"gopkg.in/guregu/null.v4.String\" (default: *json.RawMessage)",
+			},
 			&cli.BoolFlag{
 				Name:    "print-filenames",
 				Aliases: []string{"f"},
 				Usage:   "print the filename above the structs defined within",
 			},
+			&cli.StringFlag{
+				Name:    "package",
+				Aliases: []string{"p"},
+				Usage:   "produce a complete Go file in package `NAME`, including the required imports",
+			},
+			&cli.BoolFlag{
+				Name:    "generated-header",
+				Aliases: []string{"g"},
+				Usage:   "add a \"Code generated ... DO NOT EDIT.\" comment to the top of the file (requires --package)",
+			},
+			&cli.StringFlag{
+				Name:  "output-format",
+				Usage: "render the results as `FORMAT`: \"go\" for Go types, or \"jsonschema\" for a JSON Schema",
+				Value: outputGo,
+			},
 			&cli.StringFlag{
 				Name:    "out-file",
 				Aliases: []string{"o"},
 				Usage:   "write the results to `FILE`",
 			},
+			&cli.BoolFlag{
+				Name: "check",
+				Usage: "compare the results with --out-file, or the files of the manifest with \"generate\", rather " +
+					"than writing them; prints a unified diff and fails if they differ",
+			},
+			&cli.StringFlag{
+				Name: "config",
+				Usage: "read options from the config `FILE` rather than the closest " + configYAML + " or " +
+					configJSON + " found in the current directory and its parents",
+			},
+			&cli.BoolFlag{
+				Name:  "no-config",
+				Usage: "don't look for a config file",
+			},
 			&cli.BoolFlag{
 				Name:    "debug",
 				Aliases: []string{"d"},
@@ -65,14 +218,11 @@ func run() error {
 		Before: setDebug,
 		Commands: []*cli.Command{
 			httpCommand(),
+			openAPICommand(),
+			configCommand(),
+			generateCommand(),
 		},
 	}
-
-	if err := app.Run(os.Args); err != nil {
-		return fmt.Errorf("ERROR: %w", err)
-	}
-
-	return nil
 }
 
 func setDebug(ctx *cli.Context) error {
@@ -92,6 +242,10 @@ func isStdin() bool {
 }
 
 func genStructs(ctx *cli.Context) error {
+	if err := applyConfig(ctx); err != nil {
+		return err
+	}
+
 	inputs, err := getInputs(ctx)
 	if err != nil {
 		return err
@@ -101,74 +255,414 @@ func genStructs(ctx *cli.Context) error {
 		cli.ShowAppHelpAndExit(ctx, 1)
 	}
 
-	outFile := os.Stdout
-
-	if outputPath := ctx.String("out-file"); outputPath != "" {
-		outFile, err = os.Create(outputPath)
-		if err != nil {
-			return fmt.Errorf("failed to create out-file %q: %w", outputPath, err)
+	// in check mode, the results are compared with --out-file rather than written to it
+	if ctx.Bool("check") {
+		var result bytes.Buffer
+		if err := writeStructs(ctx, inputs, &result); err != nil {
+			return err
 		}
 
+		return checkOutFile(ctx, result.Bytes())
+	}
+
+	outFile, err := getOutFile(ctx)
+	if err != nil {
+		return err
+	}
+
+	if outFile != os.Stdout {
 		defer outFile.Close()
 	}
 
-	formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{
-		SortFields:    ctx.Bool("sort-fields"),
-		ValueComments: ctx.Bool("value-comments"),
-		InlineStructs: ctx.Bool("inline-structs"),
-	})
+	return writeStructs(ctx, inputs, outFile)
+}
+
+// writeStructs renders the structs of inputs to out.
+func writeStructs(ctx *cli.Context, inputs []*os.File, out io.Writer) error {
+	formatterOpts, err := getFormatterOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	outputFormat := ctx.String("output-format")
+
+	formatter, err := newFormatter(outputFormat, formatterOpts)
 	if err != nil {
 		return fmt.Errorf("failed to set up formatter: %w", err)
 	}
 
-	for _, input := range inputs {
-		jStructs, err := parseInput(input)
+	namer, err := jsonstruct.NewTypeNamer(ctx.String("name"))
+	if err != nil {
+		return fmt.Errorf("failed to set up names: %w", err)
+	}
+
+	inputOpts := &inputOptions{
+		parserOpts: getParserOptions(ctx),
+		namer:      namer,
+		schema:     ctx.Bool("schema"),
+	}
+
+	// in file mode, everything has to be rendered together to get a single package clause and import block, and a JSON
+	// Schema is a single document
+	if formatterOpts.PackageName != "" || outputFormat == outputJSONSchema {
+		return genFile(formatter, inputOpts, inputs, out)
+	}
+
+	// everything is parsed first to name the structs of all inputs before printing any of them
+	parsed := make([]jsonstruct.JSONStructs, len(inputs))
+
+	for i, input := range inputs {
+		jStructs, err := parseInput(input, inputOpts)
 		if err != nil {
 			return err
 		}
 
+		parsed[i] = jStructs
+	}
+
+	if err := namer.Done(); err != nil {
+		return fmt.Errorf("failed to name structs: %w", err)
+	}
+
+	// overrides are only reported if they don't select a field of any of the inputs
+	var unmatched []string
+
+	for i, input := range inputs {
 		// print out comments with the name of the file where we saw the struct
 		if ctx.Bool("print-filenames") {
 			spacer := strings.Repeat("=", len(input.Name()))
-			fmt.Fprintf(outFile, "// %s\n// %s\n// %s\n", spacer, input.Name(), spacer)
+			fmt.Fprintf(out, "// %s\n// %s\n// %s\n", spacer, input.Name(), spacer)
 		}
 
-		result, err := formatter.FormatStructs(jStructs...)
+		result, err := formatter.FormatStructs(parsed[i]...)
 		if err != nil {
 			return fmt.Errorf("failed to format structs: %w", err)
 		}
 
-		fmt.Fprintf(outFile, "%s\n", result)
+		fmt.Fprintf(out, "%s\n", result)
+
+		if current := unmatchedOverrides(formatter); i == 0 {
+			unmatched = slices.Clone(current)
+		} else {
+			unmatched = slices.DeleteFunc(unmatched, func(selector string) bool {
+				return !slices.Contains(current, selector)
+			})
+		}
+
+		// the types of the next inputs are renamed to avoid the ones printed so far
+		names, err := declaredNames([]byte("package temp\n" + result))
+		if err != nil {
+			return err
+		}
+
+		formatterOpts.ReservedNames = append(formatterOpts.ReservedNames, names...)
+	}
+
+	warnUnmatchedOverrides(unmatched)
+
+	return nil
+}
+
+// getOutFile returns the file passed to --out-file, or STDOUT if there isn't one.
+func getOutFile(ctx *cli.Context) (*os.File, error) {
+	outputPath := ctx.String("out-file")
+	if outputPath == "" {
+		return os.Stdout, nil
+	}
+
+	outFile, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create out-file %q: %w", outputPath, err)
+	}
+
+	return outFile, nil
+}
+
+// getFormatterOptions returns the FormatterOptions set by the global flags.
+func getFormatterOptions(ctx *cli.Context) (*jsonstruct.FormatterOptions, error) {
+	typeNames, err := parseTypeNames(ctx.StringSlice("type-name"))
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := parseTags(ctx.StringSlice("tag"))
+	if err != nil {
+		return nil, err
+	}
+
+	formatTypes, err := parseFormatTypes(ctx.StringSlice("format-type"))
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := getOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonstruct.FormatterOptions{
+		SortFields:      ctx.Bool("sort-fields"),
+		ValueComments:   ctx.Bool("value-comments"),
+		InlineStructs:   ctx.Bool("inline-structs"),
+		PackageName:     ctx.String("package"),
+		GeneratedHeader: ctx.Bool("generated-header"),
+		NullType:        ctx.String("null-type"),
+		DedupeStructs:   ctx.Bool("dedupe-structs"),
+		DetectRecursion: ctx.Bool("recursive"),
+		DedupeNaming:    jsonstruct.NamingPolicy(ctx.String("dedupe-naming")),
+		TypeNames:       typeNames,
+		CollisionNaming: jsonstruct.CollisionPolicy(ctx.String("collision-naming")),
+		Tags:            tags,
+		AlwaysTag:       ctx.Bool("always-tag"),
+		DetectEnums:     ctx.Bool("enums"),
+		EnumMaxValues:   ctx.Int("enum-max-values"),
+		EnumMethods:     ctx.Bool("enum-methods"),
+		Overrides:       overrides,
+		FormatGoTypes:   formatTypes,
+	}, nil
+}
+
+// getParserOptions returns the ParserOptions set by the global flags.
+func getParserOptions(ctx *cli.Context) *jsonstruct.ParserOptions {
+	return &jsonstruct.ParserOptions{
+		InferTime:    ctx.Bool("infer-time"),
+		InferNumbers: ctx.Bool("infer-numbers"),
+		InferFormats: ctx.Bool("infer-formats"),
+		DetectMaps:   ctx.Bool("detect-maps"),
+		MapKeys:      ctx.StringSlice("map-key"),
+		Lenient:      ctx.Bool("lenient"),
+		NDJSON:       ctx.Bool("ndjson"),
+		InputFormat:  jsonstruct.InputFormat(ctx.String("input-format")),
+	}
+}
+
+const (
+	outputGo         = "go"
+	outputJSONSchema = "jsonschema"
+)
+
+// newFormatter returns the formatter rendering results in outputFormat.
+func newFormatter(outputFormat string, opts *jsonstruct.FormatterOptions) (jsonstruct.StructFormatter, error) {
+	switch outputFormat {
+	case outputGo:
+		//nolint:wrapcheck // wrapped by genStructs
+		return jsonstruct.NewFormatter(opts)
+	case outputJSONSchema:
+		//nolint:wrapcheck // wrapped by genStructs
+		return jsonstruct.NewSchemaFormatter(opts)
+	}
+
+	return nil, fmt.Errorf("invalid output format %q", outputFormat)
+}
+
+// parseTypeNames turns the "OLD=NEW" values passed to --type-name into a map.
+func parseTypeNames(values []string) (map[string]string, error) {
+	results := map[string]string{}
+
+	for _, value := range values {
+		oldName, newName, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid type name %q, expecting OLD=NEW", value)
+		}
+
+		results[oldName] = newName
+	}
+
+	return results, nil
+}
+
+// parseTags turns the "KEY[:NAMING][:omitempty]" values passed to --tag into TagOptions.
+func parseTags(values []string) ([]jsonstruct.TagOptions, error) {
+	results := []jsonstruct.TagOptions{}
+
+	for _, value := range values {
+		parts := strings.Split(value, ":")
+		tag := jsonstruct.TagOptions{Key: parts[0]}
+
+		for _, part := range parts[1:] {
+			switch {
+			case part == "omitempty":
+				tag.OmitEmpty = true
+			case tag.Naming == "":
+				tag.Naming = jsonstruct.TagNaming(part)
+			default:
+				return nil, fmt.Errorf("invalid tag %q, expecting KEY[:NAMING][:omitempty]", value)
+			}
+		}
+
+		results = append(results, tag)
 	}
 
+	return results, nil
+}
+
+// parseFormatTypes returns the Go types of the formats in the "FORMAT=TYPE" values passed to --format-type.
+func parseFormatTypes(values []string) (map[jsonstruct.StringFormat]string, error) {
+	results := map[jsonstruct.StringFormat]string{}
+
+	for _, value := range values {
+		format, goType, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid format type %q, expecting FORMAT=TYPE", value)
+		}
+
+		results[jsonstruct.StringFormat(format)] = goType
+	}
+
+	return results, nil
+}
+
+// getOverrides returns the overrides in the file passed to --overrides, or those listed in the config file.
+func getOverrides(ctx *cli.Context) ([]jsonstruct.Override, error) {
+	path := ctx.String("overrides")
+	if path == "" {
+		overrides, _ := ctx.App.Metadata[configOverridesKey].([]jsonstruct.Override)
+
+		return overrides, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides: %w", err)
+	}
+
+	overrides, err := jsonstruct.ParseOverrides(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse overrides from %q: %w", path, err)
+	}
+
+	return overrides, nil
+}
+
+// inputOptions defines how the inputs are parsed and named.
+type inputOptions struct {
+	parserOpts *jsonstruct.ParserOptions
+	namer      *jsonstruct.TypeNamer
+	// schema parses every input as a JSON Schema rather than a sample
+	schema bool
+}
+
+// genFile renders the structs of every input together, as a single file written to out.
+func genFile(formatter jsonstruct.StructFormatter, inputOpts *inputOptions, inputs []*os.File, out io.Writer) error {
+	allStructs := jsonstruct.JSONStructs{}
+
+	for _, input := range inputs {
+		jStructs, err := parseInput(input, inputOpts)
+		if err != nil {
+			return err
+		}
+
+		allStructs = append(allStructs, jStructs...)
+	}
+
+	if err := inputOpts.namer.Done(); err != nil {
+		return fmt.Errorf("failed to name structs: %w", err)
+	}
+
+	result, err := formatter.FormatStructs(allStructs...)
+	if err != nil {
+		return fmt.Errorf("failed to format file: %w", err)
+	}
+
+	warnUnmatchedOverrides(unmatchedOverrides(formatter))
+
+	fmt.Fprint(out, result)
+
 	return nil
 }
 
-func parseInput(input *os.File) (jsonstruct.JSONStructs, error) {
+// unmatchedOverrides returns the overrides that didn't select any field in the last call to FormatStructs of formatter.
+// Only Go code is affected by overrides.
+func unmatchedOverrides(formatter jsonstruct.StructFormatter) []string {
+	if goFormatter, ok := formatter.(*jsonstruct.Formatter); ok {
+		return goFormatter.UnmatchedOverrides()
+	}
+
+	return nil
+}
+
+// warnUnmatchedOverrides logs the overrides described by unmatched, which are likely to have a typo in their path or
+// key.
+func warnUnmatchedOverrides(unmatched []string) {
+	for _, selector := range unmatched {
+		log.Warn("override doesn't select any field", "override", selector)
+	}
+}
+
+func parseInput(input *os.File, inputOpts *inputOptions) (jsonstruct.JSONStructs, error) {
 	defer func() {
 		input.Close()
 
 		log.Debug("closed input file", "file", input.Name())
 	}()
 
-	parser := jsonstruct.NewParser(input, log)
-
-	jStructs, err := parser.Start()
+	jStructs, err := startParser(input, inputOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse input %q: %w", input.Name(), err)
 	}
 
-	// set the names of the top-level structs from our example file based on the file's name
-	goFileName := jsonstruct.GetFileGoName(input.Name())
+	// e.g. "Order" rather than "OrderSchema" for order.schema.json
+	inputName := input.Name()
+	if isSchemaFile(inputName) {
+		inputName = inputName[:len(inputName)-len(schemaExt)]
+	}
 
-	for i := 0; i < len(jStructs); i++ {
-		structName := fmt.Sprintf("%s%d", goFileName, i+1)
-		jStructs[i].SetName(structName)
+	// set the names of the top-level structs from our example file based on the file's name, unless --name was used
+	if err := inputOpts.namer.NameStructs(inputName, jStructs); err != nil {
+		return nil, fmt.Errorf("failed to name structs from %q: %w", input.Name(), err)
 	}
 
 	return jStructs, nil
 }
 
+// startParser parses input as a JSON Schema if --schema was used or it is a .schema.json file, and as samples
+// otherwise.
+func startParser(input *os.File, inputOpts *inputOptions) (jsonstruct.JSONStructs, error) {
+	if inputOpts.schema || isSchemaFile(input.Name()) {
+		//nolint:wrapcheck // wrapped by parseInput
+		return jsonstruct.NewSchemaParser(input, log).Start()
+	}
+
+	parser, err := jsonstruct.NewParserWithOptions(input, log, fileParserOptions(input.Name(), inputOpts.parserOpts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up parser: %w", err)
+	}
+
+	//nolint:wrapcheck // wrapped by parseInput
+	return parser.Start()
+}
+
+// schemaExt is the extension of files that are always parsed as JSON Schemas.
+const schemaExt = ".schema.json"
+
+func isSchemaFile(fileName string) bool {
+	return strings.HasSuffix(strings.ToLower(fileName), schemaExt)
+}
+
+// fileParserOptions returns the options used to parse the file called fileName, based on its extension: JSONC and
+// JSON5 files are always parsed in lenient mode, NDJSON / JSON Lines files in NDJSON mode, and YAML / TOML files as
+// such, unless --input-format was used.
+func fileParserOptions(fileName string, parserOpts *jsonstruct.ParserOptions) *jsonstruct.ParserOptions {
+	fileOpts := *parserOpts
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".jsonc", ".json5":
+		fileOpts.Lenient = true
+	case ".ndjson", ".jsonl":
+		fileOpts.NDJSON = true
+	case ".yaml", ".yml":
+		if fileOpts.InputFormat == "" {
+			fileOpts.InputFormat = jsonstruct.InputYAML
+		}
+	case ".toml":
+		if fileOpts.InputFormat == "" {
+			fileOpts.InputFormat = jsonstruct.InputTOML
+		}
+	}
+
+	return &fileOpts
+}
+
 func getInputs(ctx *cli.Context) ([]*os.File, error) {
 	inputs := []*os.File{}
 
@@ -194,5 +688,13 @@ func getInputs(ctx *cli.Context) ([]*os.File, error) {
 func main() {
 	if err := run(); err != nil {
 		log.Error("failed to execute", "err", err)
+
+		// show where the problem is in the input
+		parseErr := &jsonstruct.ParseError{}
+		if errors.As(err, &parseErr) && parseErr.Snippet != "" {
+			fmt.Fprintf(os.Stderr, "\n%s\n", parseErr.Snippet)
+		}
+
+		os.Exit(1)
 	}
 }
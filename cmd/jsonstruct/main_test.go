@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+// newTestContext returns the context of the App run with the global flags in args.
+func newTestContext(t *testing.T, args ...string) *cli.Context {
+	t.Helper()
+
+	var result *cli.Context
+
+	app := newApp()
+	app.Action = func(ctx *cli.Context) error {
+		result = ctx
+
+		return nil
+	}
+
+	assert.Nil(t, app.Run(append([]string{app.Name}, args...)))
+
+	return result
+}
+
+// writeTestFile writes content to the file at path, relative to dir, creating its directory.
+func writeTestFile(t *testing.T, dir, path, content string) string {
+	t.Helper()
+
+	path = filepath.Join(dir, path)
+
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestWriteStructs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "shared_nested_key",
+			args: []string{"-n", "Order,Cart"},
+			expected: "type Order struct {\n\tID   int64 `json:\"id\"`\n\tItem *Item `json:\"item\"`\n}\n\n" +
+				"type Item struct {\n\tA int64 `json:\"a\"`\n}\n\n\n" +
+				"type Cart struct {\n\tItem *CartItem `json:\"item\"`\n}\n\n" +
+				"type CartItem struct {\n\tB string `json:\"b\"`\n}",
+		},
+		{
+			name: "file",
+			args: []string{"-n", "Order,Cart", "-p", "models"},
+			expected: "package models\n\ntype Order struct {\n\tID   int64      `json:\"id\"`\n" +
+				"\tItem *OrderItem `json:\"item\"`\n}\n\ntype OrderItem struct {\n\tA int64 `json:\"a\"`\n}\n\n" +
+				"type Cart struct {\n\tItem *CartItem `json:\"item\"`\n}\n\n" +
+				"type CartItem struct {\n\tB string `json:\"b\"`\n}",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			inputs := []*os.File{}
+
+			for _, path := range []string{
+				writeTestFile(t, dir, "order.json", `{"id": 1, "item": {"a": 1}}`),
+				writeTestFile(t, dir, "cart.json", `{"item": {"b": "x"}}`),
+			} {
+				input, err := os.Open(path)
+				assert.Nil(t, err)
+
+				defer input.Close()
+
+				inputs = append(inputs, input)
+			}
+
+			var out bytes.Buffer
+
+			err := writeStructs(newTestContext(t, test.args...), inputs, &out)
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(out.String()))
+		})
+	}
+}
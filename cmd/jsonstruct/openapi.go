@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cneill/jsonstruct"
+	"github.com/urfave/cli/v2"
+)
+
+var errNoBodies = errors.New("no JSON bodies found")
+
+// genOpenAPI renders the types of every body in an OpenAPI document together, using the global flags.
+func genOpenAPI(ctx *cli.Context) error {
+	if err := applyConfig(ctx); err != nil {
+		return err
+	}
+
+	var input io.Reader = os.Stdin
+
+	switch ctx.NArg() {
+	case 0:
+		if !isStdin() {
+			cli.ShowSubcommandHelpAndExit(ctx, 1)
+		}
+	case 1:
+		file, err := os.Open(ctx.Args().First())
+		if err != nil {
+			return fmt.Errorf("failed to open file %q: %w", ctx.Args().First(), err)
+		}
+
+		defer file.Close()
+
+		input = file
+	default:
+		return fmt.Errorf("expecting a single OpenAPI document, got %d", ctx.NArg())
+	}
+
+	formatterOpts, err := getFormatterOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	formatter, err := newFormatter(ctx.String("output-format"), formatterOpts)
+	if err != nil {
+		return fmt.Errorf("failed to set up formatter: %w", err)
+	}
+
+	parser, err := jsonstruct.NewOpenAPIParser(input, log, &jsonstruct.OpenAPIOptions{
+		ParserOptions: getParserOptions(ctx),
+		PreferSchemas: ctx.Bool("prefer-schemas"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up OpenAPI parser: %w", err)
+	}
+
+	jStructs, err := parser.Start()
+	if err != nil {
+		return fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	if len(jStructs) == 0 {
+		return errNoBodies
+	}
+
+	result, err := formatter.FormatStructs(jStructs...)
+	if err != nil {
+		return fmt.Errorf("failed to format structs: %w", err)
+	}
+
+	if ctx.Bool("check") {
+		return checkOutFile(ctx, []byte(result))
+	}
+
+	outFile, err := getOutFile(ctx)
+	if err != nil {
+		return err
+	}
+
+	if outFile != os.Stdout {
+		defer outFile.Close()
+	}
+
+	fmt.Fprint(outFile, result)
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package jsonstruct
+
+import "strconv"
+
+// CollisionPolicy decides how nested structs that would share a name with a differently-shaped struct are renamed.
+type CollisionPolicy string
+
+const (
+	// CollisionParent prefixes the names of colliding structs with the name of the struct containing the first
+	// instance of each shape, e.g. "OrderItems" and "CartItems". Numbers are appended if that isn't enough to tell them
+	// apart.
+	CollisionParent CollisionPolicy = "parent"
+	// CollisionNumber keeps the name of the first struct declared and appends a number to the rest, e.g. "Items" and
+	// "Items2".
+	CollisionNumber CollisionPolicy = "number"
+)
+
+// maxCollisionPasses bounds the number of times resolveCollisions will rename structs, since renaming a struct can
+// cause a new collision with a struct further down the tree.
+const maxCollisionPasses = 100
+
+// typeUse is a field holding a struct, along with the struct containing it.
+type typeUse struct {
+	parent *JSONStruct
+	field  *Field
+	shape  string
+}
+
+// resolveCollisions renames nested structs that have the same name as a differently-shaped struct, as one of the
+// top-level inputs, or as one of the ReservedNames, so that every declaration in the package has a unique name.
+func (f *FormatterOptions) resolveCollisions(inputs []*JSONStruct) {
+	for pass := 0; pass < maxCollisionPasses; pass++ {
+		reserved := map[string]bool{}
+
+		for _, name := range f.ReservedNames {
+			reserved[name] = true
+		}
+
+		for _, input := range inputs {
+			reserved[input.Name()] = true
+		}
+
+		uses := map[string][]typeUse{}
+		names := []string{}
+
+		walkFields(inputs, func(parent *JSONStruct, field *Field) {
+			name := field.TypeName()
+			if _, ok := uses[name]; !ok {
+				names = append(names, name)
+			}
+
+			uses[name] = append(uses[name], typeUse{parent: parent, field: field, shape: field.GetStruct().shape()})
+		})
+
+		renamed := false
+
+		for _, name := range names {
+			if f.renameCollisions(name, uses[name], reserved[name]) {
+				renamed = true
+			}
+		}
+
+		if !renamed {
+			return
+		}
+	}
+}
+
+// renameCollisions renames the uses of the struct type called name if they have different shapes, or if the name is
+// reserved by one of the top-level inputs or the ReservedNames. Returns true if anything was renamed.
+func (f *FormatterOptions) renameCollisions(name string, uses []typeUse, reserved bool) bool {
+	shapeIndexes := map[string]int{}
+
+	for _, use := range uses {
+		if _, ok := shapeIndexes[use.shape]; !ok {
+			shapeIndexes[use.shape] = len(shapeIndexes)
+		}
+	}
+
+	if len(shapeIndexes) < 2 && !reserved {
+		return false
+	}
+
+	// every use of a shape gets the name chosen for its first use, and assigned makes sure two different shapes don't
+	// end up with the same name
+	shapeNames := map[string]string{}
+	assigned := map[string]bool{}
+
+	for _, use := range uses {
+		newName, ok := shapeNames[use.shape]
+		if !ok {
+			baseName := f.collisionName(name, use, shapeIndexes[use.shape], reserved)
+			newName = baseName
+
+			for i := 2; assigned[newName]; i++ {
+				newName = baseName + strconv.Itoa(i)
+			}
+
+			shapeNames[use.shape] = newName
+			assigned[newName] = true
+		}
+
+		use.field.SetTypeName(newName)
+	}
+
+	return true
+}
+
+// collisionName returns the new name for a use of the struct type called name, according to the CollisionNaming
+// option.
+func (f *FormatterOptions) collisionName(name string, use typeUse, shapeIndex int, reserved bool) string {
+	if f.CollisionNaming != CollisionNumber {
+		return use.parent.Name() + name
+	}
+
+	// the top-level input keeps a reserved name, so numbering starts one later
+	if reserved {
+		shapeIndex++
+	}
+
+	if shapeIndex == 0 {
+		return name
+	}
+
+	return name + strconv.Itoa(shapeIndex+1)
+}
@@ -0,0 +1,65 @@
+package jsonstruct
+
+// NamingPolicy decides which name is used for a type shared by several structurally identical structs.
+type NamingPolicy string
+
+const (
+	// NamingFirstSeen uses the name of the first struct, in the order they are declared.
+	NamingFirstSeen NamingPolicy = "first"
+	// NamingShortest uses the shortest name, falling back to the first struct's name if there is a tie.
+	NamingShortest NamingPolicy = "shortest"
+)
+
+// dedupeStructs gives nested structs with identical shapes the same type name, so they are only declared once. Since a
+// struct's shape includes the names of the structs nested within it, this repeats until nothing changes.
+func (f *FormatterOptions) dedupeStructs(inputs []*JSONStruct) {
+	for changed := true; changed; {
+		changed = false
+
+		groups := map[string][]*Field{}
+		shapes := []string{}
+
+		walkFields(inputs, func(_ *JSONStruct, field *Field) {
+			shape := field.GetStruct().shape()
+			if _, ok := groups[shape]; !ok {
+				shapes = append(shapes, shape)
+			}
+
+			groups[shape] = append(groups[shape], field)
+		})
+
+		for _, shape := range shapes {
+			name := f.sharedName(groups[shape])
+
+			for _, field := range groups[shape] {
+				if field.TypeName() != name {
+					field.SetTypeName(name)
+
+					changed = true
+				}
+			}
+		}
+	}
+}
+
+// sharedName returns the name to use for the type shared by fields, according to the DedupeNaming and TypeNames
+// options.
+func (f *FormatterOptions) sharedName(fields []*Field) string {
+	for _, field := range fields {
+		if name, ok := f.TypeNames[field.TypeName()]; ok {
+			return name
+		}
+	}
+
+	name := fields[0].TypeName()
+
+	if f.DedupeNaming == NamingShortest {
+		for _, field := range fields[1:] {
+			if len(field.TypeName()) < len(name) {
+				name = field.TypeName()
+			}
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,143 @@
+package jsonstruct
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// InputFormat is the format of the input of a Parser.
+type InputFormat string
+
+const (
+	// InputJSON is JSON, or JSONC / JSON5 in lenient mode. This is the default.
+	InputJSON InputFormat = "json"
+	// InputYAML is a stream of YAML documents.
+	InputYAML InputFormat = "yaml"
+	// InputTOML is a TOML document.
+	InputTOML InputFormat = "toml"
+)
+
+// isDocumentFormat returns true if the input is decoded as a whole by the YAML or TOML adapter, rather than parsed
+// token by token.
+func (p *ParserOptions) isDocumentFormat() bool {
+	return p.InputFormat == InputYAML || p.InputFormat == InputTOML
+}
+
+// startDocuments decodes YAML or TOML documents and converts them to the values parseValue returns for the equivalent
+// JSON, so that they become JSONStructs the same way JSON values do. In NDJSON mode, the documents are merged.
+func (p *Parser) startDocuments() (JSONStructs, error) {
+	var (
+		docs []any
+		err  error
+	)
+
+	if p.InputFormat == InputTOML {
+		var doc any
+		if doc, err = decodeTOML(p.document); err == nil {
+			docs = []any{doc}
+		}
+	} else {
+		docs, err = decodeYAMLDocuments(p.document)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s input: %w", p.InputFormat, err)
+	}
+
+	if p.NDJSON {
+		return p.mergeDocuments(docs)
+	}
+
+	results := JSONStructs{}
+
+	for _, doc := range docs {
+		results = append(results, p.documentStruct(p.rootStruct(p.documentValue(doc))))
+	}
+
+	return results, nil
+}
+
+// documentStruct marks the value of js, if it is a named type, as decoded from the input format, so that it is typed
+// accordingly.
+func (p *Parser) documentStruct(js *JSONStruct) *JSONStruct {
+	if js.value != nil {
+		js.value.setTagKey(string(p.InputFormat))
+	}
+
+	return js
+}
+
+// mergeDocuments merges docs into a single JSONStruct, the same way NDJSON values are merged.
+func (p *Parser) mergeDocuments(docs []any) (JSONStructs, error) {
+	var (
+		merged any
+		ok     bool
+	)
+
+	for i, doc := range docs {
+		value := p.documentValue(doc)
+
+		// like NDJSON, arrays contribute each of their elements
+		samples, isSlice := value.([]any)
+		if js, isStruct := value.(*JSONStruct); isStruct {
+			samples = []any{p.mapOrStruct(rootKey, js)}
+		} else if !isSlice {
+			samples = []any{value}
+		}
+
+		if merged, ok = mergeSamples(merged, samples); !ok {
+			return nil, fmt.Errorf("document %d: %w", i+1, ErrIncompatibleSamples)
+		}
+	}
+
+	results, err := mergedStructs(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, js := range results {
+		p.documentStruct(js)
+	}
+
+	return results, nil
+}
+
+// documentValue converts a value decoded by a YAML or TOML adapter to the value parseValue returns for the equivalent
+// JSON. The fields of objects are tagged for the input format.
+func (p *Parser) documentValue(value any) any {
+	switch val := value.(type) {
+	case *schemaObject:
+		result := New()
+
+		for _, key := range val.keys {
+			fieldValue := p.documentValue(val.values[key])
+			if js, ok := fieldValue.(*JSONStruct); ok {
+				fieldValue = p.mapOrStruct(key, js)
+			}
+
+			result.AddFields(NewField().SetName(key).SetValue(fieldValue).setTagKey(string(p.InputFormat)))
+		}
+
+		return result
+	case []any:
+		results := make([]any, 0, len(val))
+
+		for _, item := range val {
+			itemValue := p.documentValue(item)
+			if js, ok := itemValue.(*JSONStruct); ok {
+				itemValue = p.mapOrStruct("", js)
+			}
+
+			results = append(results, itemValue)
+		}
+
+		return results
+	case json.Number:
+		return numberValue(val)
+	case string:
+		return p.classifyString(val)
+	}
+
+	// bools, nulls, and TOML's dates and times
+	return value
+}
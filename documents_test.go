@@ -0,0 +1,158 @@
+package jsonstruct_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestParserDocuments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     *jsonstruct.ParserOptions
+		expected string
+	}{
+		{
+			name: "yaml",
+			input: "name: api\nport: 0x1F90\nratio: 1e3\nweight: 1_000\nenabled: true\nhosts:\n  - {host: a, weight: 1}\n" +
+				"  - {host: b}\nlimits: &limits\n  cpu: .5\nother: *limits\nnothing: ~\n",
+			opts: &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML},
+			expected: "type Root struct {\n\tName    string   `yaml:\"name\"`\n\tPort    int64    `yaml:\"port\"`\n" +
+				"\tRatio   float64  `yaml:\"ratio\"`\n\tWeight  int64    `yaml:\"weight\"`\n" +
+				"\tEnabled bool     `yaml:\"enabled\"`\n\tHosts   []*Hosts `yaml:\"hosts\"`\n" +
+				"\tLimits  *Limits  `yaml:\"limits\"`\n\tOther   *Other   `yaml:\"other\"`\n" +
+				"\tNothing any      `yaml:\"nothing\"`\n}\n\ntype Hosts struct {\n\tHost   string `yaml:\"host\"`\n" +
+				"\tWeight int64  `yaml:\"weight,omitempty\"`\n}\n\ntype Limits struct {\n\tCPU float64 `yaml:\"cpu\"`\n}\n\n" +
+				"type Other struct {\n\tCPU float64 `yaml:\"cpu\"`\n}",
+		},
+		{
+			name:  "yaml_mixed",
+			input: "mixed: [1, a]\nitems:\n  - {value: 1}\n  - {value: {a: 1}}\n  - {value: ~}\nlabels: {a: 1, b: x}\n",
+			opts:  &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML, MapKeys: []string{"labels"}},
+			expected: "type Root struct {\n\tMixed  []any          `yaml:\"mixed\"`\n" +
+				"\tItems  []*Items       `yaml:\"items\"`\n" +
+				"\tLabels map[string]any `yaml:\"labels\"`\n}\n\ntype Items struct {\n\tValue any `yaml:\"value\"`\n}",
+		},
+		{
+			name:     "yaml_root_mixed",
+			input:    "- 1\n- a\n",
+			opts:     &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML},
+			expected: "type Root []any",
+		},
+		{
+			name:  "yaml_infer_time",
+			input: "created: 2024-01-02T03:04:05Z\nday: 2024-01-02\n",
+			opts:  &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML, InferTime: true},
+			expected: "type Root struct {\n\tCreated time.Time `yaml:\"created\"`\n\tDay     Date      `yaml:\"day\"`\n}\n" +
+				dateDeclaration,
+		},
+		{
+			name:     "yaml_documents_merged",
+			input:    "a: 1\n---\na: 2\nb: x\n---\n- a: 3\n",
+			opts:     &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML, NDJSON: true},
+			expected: "type Root struct {\n\tA int64  `yaml:\"a\"`\n\tB string `yaml:\"b,omitempty\"`\n}",
+		},
+		{
+			name:     "yaml_root_array",
+			input:    "- 1\n- 2\n",
+			opts:     &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML},
+			expected: "type Root []int64",
+		},
+		{
+			name:  "yaml_maps",
+			input: "users:\n  \"1\": {name: a}\n  \"2\": {name: b}\n",
+			opts:  &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML, DetectMaps: true},
+			expected: "type Root struct {\n\tUsers map[string]*Users `yaml:\"users\"`\n}\n\ntype Users struct {\n" +
+				"\tName string `yaml:\"name\"`\n}",
+		},
+		{
+			name: "toml",
+			input: "title = \"x\"\n\n[owner]\nname = \"Tom\"\ndob = 1979-05-27T07:32:00-08:00\nday = 1979-05-27\n\n" +
+				"[database]\nports = [8000, 8001]\ntemp = 79.5\n\n[[products]]\nname = \"Hammer\"\nsku = 738594937\n\n" +
+				"[[products]]\nname = \"Nail\"\ncolor = \"gray\"\n",
+			opts: &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputTOML},
+			expected: "type Root struct {\n\tTitle    string      `toml:\"title\"`\n\tOwner    *Owner      `toml:\"owner\"`\n" +
+				"\tDatabase *Database   `toml:\"database\"`\n\tProducts []*Products `toml:\"products\"`\n}\n\n" +
+				"type Owner struct {\n\tName string    `toml:\"name\"`\n\tDob  time.Time `toml:\"dob\"`\n" +
+				"\tDay  Date      `toml:\"day\"`\n}\n\ntype Database struct {\n\tPorts []int64 `toml:\"ports\"`\n" +
+				"\tTemp  float64 `toml:\"temp\"`\n}\n\ntype Products struct {\n\tName  string `toml:\"name\"`\n" +
+				"\tSku   int64  `toml:\"sku,omitempty\"`\n\tColor string `toml:\"color,omitempty\"`\n}\n" +
+				dateDeclaration,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(test.input), slog.Default(), test.opts)
+			assert.Nil(t, err)
+
+			structs, err := parser.Start()
+			assert.Nil(t, err)
+
+			if !assert.Equal(t, 1, len(structs)) {
+				return
+			}
+
+			formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{})
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs[0].SetName("Root"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(output))
+		})
+	}
+}
+
+func TestParserDocumentsErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		opts  *jsonstruct.ParserOptions
+		err   string
+	}{
+		{"invalid_yaml", "a: [1, 2\n", &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML}, ""},
+		{"invalid_toml", "a = \n", &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputTOML}, ""},
+		{
+			"incompatible_documents", "a: 1\n---\n1\n",
+			&jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML, NDJSON: true}, "",
+		},
+		{
+			"non_scalar_key", "a:\n  ? [b, c]\n  : 1\n",
+			&jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML}, "line 2: unsupported non-scalar key",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(test.input), slog.Default(), test.opts)
+			assert.Nil(t, err)
+
+			_, err = parser.Start()
+			assert.NotNil(t, err)
+
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+			}
+		})
+	}
+
+	_, err := jsonstruct.NewParserWithOptions(strings.NewReader(""), slog.Default(),
+		&jsonstruct.ParserOptions{InputFormat: "xml"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,263 @@
+package jsonstruct
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultEnumMaxValues is the largest number of distinct values a field can take to be declared as an enum when
+// FormatterOptions.EnumMaxValues isn't set.
+const DefaultEnumMaxValues = 10
+
+// maxTrackedValues bounds the number of distinct values remembered for each string field, so that fields holding free
+// text don't keep every sample in memory.
+const maxTrackedValues = 64
+
+// maxEnumValueLength is the length of the longest value an enum can have. Longer strings are most likely free text.
+const maxEnumValueLength = 64
+
+// stringValues are the distinct values a string field took in the samples it was merged from, in the order they were
+// first seen.
+type stringValues struct {
+	values  []string
+	samples int
+	// overflow is set if there were more than maxTrackedValues distinct values, in which case values is incomplete.
+	overflow bool
+	// listed is set for the values of a JSON Schema enum, which are complete even though they weren't repeated.
+	listed bool
+}
+
+// mergeStringValues returns the distinct values of the string fields in fields, which are the instances of a single
+// field in the samples being merged, or nil if any of them held anything other than strings or nulls.
+func mergeStringValues(fields []*Field) *stringValues {
+	result := &stringValues{listed: true}
+	seen := map[string]bool{}
+
+	add := func(value string) {
+		switch {
+		case seen[value]:
+		case len(result.values) == maxTrackedValues:
+			result.overflow = true
+		default:
+			seen[value] = true
+			result.values = append(result.values, value)
+		}
+	}
+
+	for _, field := range fields {
+		switch val := field.rawValue.(type) {
+		case nil:
+			if field.isJSONRaw {
+				return nil
+			}
+		case formattedString:
+			// e.g. a mix of timestamps and other strings: the values of the timestamps weren't kept
+			result.overflow = true
+		case string:
+			if field.strings == nil {
+				add(val)
+				result.samples++
+				result.listed = false
+
+				continue
+			}
+
+			for _, value := range field.strings.values {
+				add(value)
+			}
+
+			result.samples += field.strings.samples
+			result.overflow = result.overflow || field.strings.overflow
+			result.listed = result.listed && field.strings.listed
+		default:
+			return nil
+		}
+	}
+
+	if len(result.values) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+// isEnum returns true if the values look like the members of an enum: there are at least two of them and no more than
+// maxValues, they were repeated across the samples, and none of them look like free text. The values of a JSON Schema
+// enum don't need to be repeated, and there can be any number of them.
+func (s *stringValues) isEnum(maxValues int) bool {
+	if s == nil || s.overflow || len(s.values) < 2 {
+		return false
+	}
+
+	if !s.listed && (len(s.values) > maxValues || s.samples <= len(s.values)) {
+		return false
+	}
+
+	for _, value := range s.values {
+		if len(value) > maxEnumValueLength || strings.IndexFunc(value, unicode.IsControl) != -1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// enumType is a named string type declared for a field, along with its constants.
+type enumType struct {
+	name   string
+	values []string
+}
+
+// constNames returns the name of the constant declared for each of the values, or false if they don't all get valid
+// and distinct names.
+func (e *enumType) constNames() ([]string, bool) {
+	results := make([]string, 0, len(e.values))
+	seen := map[string]bool{}
+
+	for _, value := range e.values {
+		suffix := GetGoName(value)
+		if suffix == "" {
+			return nil, false
+		}
+
+		name := e.name + suffix
+		if !token.IsIdentifier(name) || seen[name] {
+			return nil, false
+		}
+
+		seen[name] = true
+
+		results = append(results, name)
+	}
+
+	return results, true
+}
+
+// key returns a string identifying the values of the enum, regardless of its name.
+func (e *enumType) key() string {
+	return strings.Join(e.values, "\x00")
+}
+
+// detectEnums declares a named string type for every string field that took a small set of values across samples, if
+// DetectEnums is set. Each type is named after the struct and field it was found in (e.g. "OrderStatus"), and fields
+// with the same name and values share a type.
+func (f *FormatterOptions) detectEnums(inputs []*JSONStruct) {
+	if !f.DetectEnums {
+		return
+	}
+
+	maxValues := f.EnumMaxValues
+	if maxValues == 0 {
+		maxValues = DefaultEnumMaxValues
+	}
+
+	structs := append([]*JSONStruct{}, inputs...)
+
+	walkFields(inputs, func(_ *JSONStruct, field *Field) {
+		structs = append(structs, field.GetStruct())
+	})
+
+	// struct names can't be reused, and enum names can only be reused for the same values
+	reserved := map[string]bool{}
+	for _, name := range f.ReservedNames {
+		reserved[name] = true
+	}
+
+	for _, js := range structs {
+		reserved[js.Name()] = true
+	}
+
+	enums := map[string]*enumType{}
+
+	for _, js := range structs {
+		for _, field := range js.typeFields() {
+			if field.goType != "" || !field.strings.isEnum(maxValues) {
+				continue
+			}
+
+			enum := &enumType{name: enumName(js, field), values: field.strings.values}
+			if _, ok := enum.constNames(); !ok {
+				continue
+			}
+
+			baseName := enum.name
+
+			for i := 2; reserved[enum.name] || (enums[enum.name] != nil && enums[enum.name].key() != enum.key()); i++ {
+				enum.name = baseName + strconv.Itoa(i)
+			}
+
+			if existing, ok := enums[enum.name]; ok {
+				enum = existing
+			}
+
+			enums[enum.name] = enum
+			field.enum = enum
+		}
+	}
+}
+
+// enumName returns the name of the enum declared for field in js, e.g. "OrderStatus" for the "status" field of
+// "Order". Fields already named after their struct aren't prefixed again.
+func enumName(js *JSONStruct, field *Field) string {
+	if strings.HasPrefix(field.Name(), js.Name()) {
+		return field.Name()
+	}
+
+	return js.Name() + field.Name()
+}
+
+// formatEnums returns the declarations of the enums used by the fields of js that haven't been declared yet, including
+// those of inlined structs.
+func (f *Formatter) formatEnums(declared map[string]bool, js *JSONStruct) string {
+	result := ""
+
+	for _, field := range js.typeFields() {
+		if f.InlineStructs && field.HasStruct() {
+			result += f.formatEnums(declared, field.GetStruct())
+
+			continue
+		}
+
+		if field.enum == nil || declared[field.enum.name] {
+			continue
+		}
+
+		declared[field.enum.name] = true
+		result += f.formatEnum(field.enum)
+	}
+
+	return result
+}
+
+// formatEnum returns the declaration of enum: a named string type, its constants, and, if EnumMethods is set, a Valid
+// method and an UnmarshalJSON method rejecting unknown values.
+func (f *Formatter) formatEnum(enum *enumType) string {
+	constNames, _ := enum.constNames()
+
+	result := fmt.Sprintf("type %s string\n\nconst (\n", enum.name)
+
+	for i, value := range enum.values {
+		result += fmt.Sprintf("%s %s = %s\n", constNames[i], enum.name, strconv.Quote(value))
+	}
+
+	result += ")\n\n"
+
+	if !f.EnumMethods {
+		return result
+	}
+
+	result += fmt.Sprintf("// Valid returns true if s is one of the known %s values.\n", enum.name)
+	result += fmt.Sprintf("func (s %s) Valid() bool {\nswitch s {\ncase %s:\nreturn true\n}\n\nreturn false\n}\n\n",
+		enum.name, strings.Join(constNames, ", "))
+
+	result += fmt.Sprintf("// UnmarshalJSON decodes s, rejecting values that aren't known %s values.\n", enum.name)
+	result += fmt.Sprintf("func (s *%s) UnmarshalJSON(data []byte) error {\nvar value string\n"+
+		"if err := json.Unmarshal(data, &value); err != nil {\nreturn err\n}\n\n"+
+		"if !%s(value).Valid() {\nreturn fmt.Errorf(\"invalid %s %%q\", value)\n}\n\n"+
+		"*s = %s(value)\n\nreturn nil\n}\n\n", enum.name, enum.name, enum.name, enum.name)
+
+	return result
+}
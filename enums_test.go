@@ -0,0 +1,125 @@
+package jsonstruct_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestFormatterEnums(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		input      string
+		parserOpts *jsonstruct.ParserOptions
+		opts       *jsonstruct.FormatterOptions
+		expected   string
+	}{
+		{
+			name:     "disabled",
+			input:    `[{"status": "a"}, {"status": "b"}, {"status": "a"}]`,
+			opts:     &jsonstruct.FormatterOptions{},
+			expected: "type Order struct {\n\tStatus string `json:\"status\"`\n}",
+		},
+		{
+			name: "slice",
+			input: `[{"status": "pending", "note": "x"}, {"status": "in progress", "note": "y"}, ` +
+				`{"status": "pending", "note": "z"}, {"status": null, "note": "w"}]`,
+			opts: &jsonstruct.FormatterOptions{DetectEnums: true},
+			expected: "type Order struct {\n\tStatus *OrderStatus `json:\"status\"`\n" +
+				"\tNote   string       `json:\"note\"`\n}\n\n" +
+				"type OrderStatus string\n\nconst (\n\tOrderStatusPending    OrderStatus = \"pending\"\n" +
+				"\tOrderStatusInProgress OrderStatus = \"in progress\"\n)",
+		},
+		{
+			name:       "ndjson",
+			input:      "{\"type\": \"a\"}\n{\"type\": \"b\"}\n{\"type\": \"b\"}\n",
+			parserOpts: &jsonstruct.ParserOptions{NDJSON: true},
+			opts:       &jsonstruct.FormatterOptions{DetectEnums: true},
+			expected: "type Order struct {\n\tType OrderType `json:\"type\"`\n}\n\ntype OrderType string\n\n" +
+				"const (\n\tOrderTypeA OrderType = \"a\"\n\tOrderTypeB OrderType = \"b\"\n)",
+		},
+		{
+			name:  "nested_and_reserved_name",
+			input: `[{"type": "a", "order_type": {"code": "x"}}, {"type": "b", "order_type": {"code": "x"}}, {"type": "a"}]`,
+			opts:  &jsonstruct.FormatterOptions{DetectEnums: true},
+			expected: "type Order struct {\n\tType      OrderType2 `json:\"type\"`\n" +
+				"\tOrderType *OrderType `json:\"order_type,omitempty\"`\n}\n\ntype OrderType2 string\n\n" +
+				"const (\n\tOrderType2A OrderType2 = \"a\"\n\tOrderType2B OrderType2 = \"b\"\n)\n\n" +
+				"type OrderType struct {\n\tCode string `json:\"code\"`\n}",
+		},
+		{
+			name:     "too_many_values",
+			input:    `[{"s": "a"}, {"s": "b"}, {"s": "c"}, {"s": "a"}]`,
+			opts:     &jsonstruct.FormatterOptions{DetectEnums: true, EnumMaxValues: 2},
+			expected: "type Order struct {\n\tS string `json:\"s\"`\n}",
+		},
+		{
+			name:     "no_repeats",
+			input:    `[{"name": "alice"}, {"name": "bob"}]`,
+			opts:     &jsonstruct.FormatterOptions{DetectEnums: true},
+			expected: "type Order struct {\n\tName string `json:\"name\"`\n}",
+		},
+		{
+			name:     "single_value",
+			input:    `[{"currency": "USD"}, {"currency": "USD"}]`,
+			opts:     &jsonstruct.FormatterOptions{DetectEnums: true},
+			expected: "type Order struct {\n\tCurrency string `json:\"currency\"`\n}",
+		},
+		{
+			name:     "invalid_const_names",
+			input:    `[{"op": "+"}, {"op": "-"}, {"op": "+"}]`,
+			opts:     &jsonstruct.FormatterOptions{DetectEnums: true},
+			expected: "type Order struct {\n\tOp string `json:\"op\"`\n}",
+		},
+		{
+			name:  "methods",
+			input: `[{"kind": "a"}, {"kind": "b"}, {"kind": "b"}]`,
+			opts:  &jsonstruct.FormatterOptions{DetectEnums: true, EnumMethods: true},
+			expected: "type Order struct {\n\tKind OrderKind `json:\"kind\"`\n}\n\ntype OrderKind string\n\n" +
+				"const (\n\tOrderKindA OrderKind = \"a\"\n\tOrderKindB OrderKind = \"b\"\n)\n\n" +
+				"// Valid returns true if s is one of the known OrderKind values.\n" +
+				"func (s OrderKind) Valid() bool {\n\tswitch s {\n\tcase OrderKindA, OrderKindB:\n\t\treturn true\n\t}\n\n" +
+				"\treturn false\n}\n\n" +
+				"// UnmarshalJSON decodes s, rejecting values that aren't known OrderKind values.\n" +
+				"func (s *OrderKind) UnmarshalJSON(data []byte) error {\n\tvar value string\n" +
+				"\tif err := json.Unmarshal(data, &value); err != nil {\n\t\treturn err\n\t}\n\n" +
+				"\tif !OrderKind(value).Valid() {\n\t\treturn fmt.Errorf(\"invalid OrderKind %q\", value)\n\t}\n\n" +
+				"\t*s = OrderKind(value)\n\n\treturn nil\n}",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parserOpts := test.parserOpts
+			if parserOpts == nil {
+				parserOpts = &jsonstruct.ParserOptions{}
+			}
+
+			parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(test.input), slog.Default(), parserOpts)
+			assert.Nil(t, err)
+
+			structs, err := parser.Start()
+			assert.Nil(t, err)
+
+			formatter, err := jsonstruct.NewFormatter(test.opts)
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs[0].SetName("Order"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(output))
+		})
+	}
+
+	_, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{DetectEnums: true, EnumMaxValues: -1})
+	assert.NotNil(t, err)
+}
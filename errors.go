@@ -0,0 +1,178 @@
+package jsonstruct
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError describes a problem with the input of a Parser, and where it was found. Use errors.As to get it from the
+// errors returned by Parser.Start.
+type ParseError struct {
+	// Offset is the number of bytes into the input where the error was found.
+	Offset int64
+	// Line and Column are the position of Offset in the input, starting at 1. Column counts characters, and is 0 if
+	// the line was too long to keep track of.
+	Line   int
+	Column int
+	// Path is the JSON path of the value being parsed when the error was found, e.g. "$.structs[2].stuff".
+	Path string
+	// Snippet is the line of input containing the error, followed by a line with a caret pointing at Column.
+	Snippet string
+	// Err is the underlying error.
+	Err error
+}
+
+func (p *ParseError) Error() string {
+	var result strings.Builder
+
+	fmt.Fprintf(&result, "line %d", p.Line)
+
+	if p.Column > 0 {
+		fmt.Fprintf(&result, ", column %d", p.Column)
+	}
+
+	if p.Path != "" {
+		fmt.Fprintf(&result, " (at %s)", p.Path)
+	}
+
+	fmt.Fprintf(&result, ": %v", p.Err)
+
+	return result.String()
+}
+
+func (p *ParseError) Unwrap() error {
+	return p.Err
+}
+
+// Detail returns the error message followed by the snippet of input showing where the error was found.
+func (p *ParseError) Detail() string {
+	if p.Snippet == "" {
+		return p.Error()
+	}
+
+	return p.Error() + "\n\n" + p.Snippet
+}
+
+const (
+	// maxPositionWindow is the amount of input kept by positionReader to describe where errors were found.
+	maxPositionWindow = 1 << 20
+	// maxSnippetWidth is the length above which lines are shortened in snippets.
+	maxSnippetWidth = 100
+	// snippetContext is the number of bytes shown on either side of an error in lines that are shortened.
+	snippetContext = 40
+)
+
+// positionReader keeps the most recent input it has read, as well as the number of lines before it, so that the
+// position of errors can be found without holding on to the whole input.
+type positionReader struct {
+	input io.Reader
+	// window contains the most recent input, starting at offset start, which is preceded by lines newlines
+	window []byte
+	start  int64
+	lines  int
+}
+
+func newPositionReader(input io.Reader) *positionReader {
+	return &positionReader{input: input}
+}
+
+func (p *positionReader) Read(buf []byte) (int, error) {
+	n, err := p.input.Read(buf)
+	p.window = append(p.window, buf[:n]...)
+
+	if len(p.window) > 2*maxPositionWindow {
+		drop := len(p.window) - maxPositionWindow
+		p.lines += bytes.Count(p.window[:drop], []byte("\n"))
+		p.start += int64(drop)
+		p.window = p.window[:copy(p.window, p.window[drop:])]
+	}
+
+	//nolint:wrapcheck // io.EOF has to be returned as-is
+	return n, err
+}
+
+// end returns the offset of the end of the input read so far.
+func (p *positionReader) end() int64 {
+	return p.start + int64(len(p.window))
+}
+
+// parseError returns a ParseError for err, found at offset while parsing the value at path.
+func (p *positionReader) parseError(offset int64, path string, err error) *ParseError {
+	result := &ParseError{Offset: offset, Path: path, Err: err}
+
+	if offset < p.start || offset > p.end() {
+		return result
+	}
+
+	result.Line, result.Column, result.Snippet = position(p.window, offset-p.start, p.start == 0)
+	result.Line += p.lines
+
+	return result
+}
+
+// position returns the line and column of offset in data, starting at 1, as well as a snippet of the line with a caret
+// pointing at the column. If data doesn't contain the start of the line (fromStart is false and there's no newline
+// before offset), the column is 0.
+func position(data []byte, offset int64, fromStart bool) (int, int, string) {
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	if lineStart == 0 && !fromStart {
+		return line, 0, ""
+	}
+
+	lineEnd := bytes.IndexByte(data[offset:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(data)
+	} else {
+		lineEnd += int(offset)
+	}
+
+	column := utf8.RuneCount(data[lineStart:offset]) + 1
+
+	return line, column, snippet(string(bytes.TrimRight(data[lineStart:lineEnd], "\r")), int(offset)-lineStart)
+}
+
+// snippet returns line followed by a caret pointing at index, showing only snippetContext bytes on either side of it
+// if the line is longer than maxSnippetWidth.
+func snippet(line string, index int) string {
+	index = min(index, len(line))
+	start, end := 0, len(line)
+
+	if len(line) > maxSnippetWidth {
+		start, end = max(0, index-snippetContext), min(len(line), index+snippetContext)
+	}
+
+	// don't cut runes in half
+	for start > 0 && !utf8.RuneStart(line[start]) {
+		start--
+	}
+
+	for end < len(line) && !utf8.RuneStart(line[end]) {
+		end++
+	}
+
+	prefix, suffix := "", ""
+	if start > 0 {
+		prefix = "..."
+	}
+
+	if end < len(line) {
+		suffix = "..."
+	}
+
+	// keep tabs so that the caret lines up with the snippet
+	caret := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+
+		return ' '
+	}, prefix+line[start:index])
+
+	return prefix + line[start:end] + suffix + "\n" + caret + "^"
+}
@@ -0,0 +1,159 @@
+package jsonstruct_test
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestParseError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		opts    *jsonstruct.ParserOptions
+		line    int
+		column  int
+		path    string
+		snippet string
+	}{
+		{
+			name:    "missing_value",
+			input:   `{"structs": [{"a": 1}, {"b": 2}, {"stuff": }]}`,
+			line:    1,
+			column:  44,
+			path:    "$.structs[2].stuff",
+			snippet: "{\"structs\": [{\"a\": 1}, {\"b\": 2}, {\"stuff\": }]}\n" + strings.Repeat(" ", 43) + "^",
+		},
+		{
+			name:    "multiple_lines",
+			input:   "{\n\t\"a\": 1,\n\t\"b\": [1, 2 3]\n}",
+			line:    3,
+			column:  13,
+			path:    "$.b[2]",
+			snippet: "\t\"b\": [1, 2 3]\n\t           ^",
+		},
+		{
+			name:    "quoted_key",
+			input:   `{"weird key": {"x": tru}}`,
+			line:    1,
+			column:  24,
+			path:    `$["weird key"].x`,
+			snippet: "{\"weird key\": {\"x\": tru}}\n" + strings.Repeat(" ", 23) + "^",
+		},
+		{
+			name:    "unexpected_eof",
+			input:   `{"a": [1, 2`,
+			line:    1,
+			column:  12,
+			path:    "$.a[2]",
+			snippet: "{\"a\": [1, 2\n" + strings.Repeat(" ", 11) + "^",
+		},
+		{
+			name:    "second_value",
+			input:   "{\"a\": 1}\n{\"a\": }",
+			line:    2,
+			column:  7,
+			path:    "$.a",
+			snippet: "{\"a\": }\n      ^",
+		},
+		{
+			name:    "long_line",
+			input:   `{"a": "` + strings.Repeat("x", 100) + `", "b": }`,
+			line:    1,
+			column:  116,
+			path:    "$.b",
+			snippet: "..." + strings.Repeat("x", 32) + "\", \"b\": }\n" + strings.Repeat(" ", 43) + "^",
+		},
+		{
+			name:    "ndjson",
+			input:   "{\"a\": 1}\n{\"a\": [}\n",
+			opts:    &jsonstruct.ParserOptions{NDJSON: true},
+			line:    2,
+			column:  8,
+			path:    "$.a",
+			snippet: "{\"a\": [}\n       ^",
+		},
+		{
+			name:    "lenient_original_snippet",
+			input:   "{\n\ta: 1, // comment\n\t/* x */ 'b': [1, 2 3,],\n}",
+			opts:    &jsonstruct.ParserOptions{Lenient: true},
+			line:    3,
+			column:  21,
+			path:    "$.b[2]",
+			snippet: "\t/* x */ 'b': [1, 2 3,],\n\t                   ^",
+		},
+		{
+			name:    "lenient_syntax",
+			input:   "{\n  a: 'unterminated\n}",
+			opts:    &jsonstruct.ParserOptions{Lenient: true},
+			line:    2,
+			column:  6,
+			snippet: "  a: 'unterminated\n     ^",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := test.opts
+			if opts == nil {
+				opts = &jsonstruct.ParserOptions{}
+			}
+
+			parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(test.input), slog.Default(), opts)
+			assert.Nil(t, err)
+
+			_, err = parser.Start()
+
+			parseErr := &jsonstruct.ParseError{}
+			if !assert.True(t, errors.As(err, &parseErr)) {
+				return
+			}
+
+			assert.Equal(t, test.line, parseErr.Line)
+			assert.Equal(t, test.column, parseErr.Column)
+			assert.Equal(t, test.path, parseErr.Path)
+			assert.Equal(t, test.snippet, parseErr.Snippet)
+			assert.True(t, strings.HasSuffix(parseErr.Detail(), "\n\n"+test.snippet))
+		})
+	}
+}
+
+func TestParseErrorLargeInput(t *testing.T) {
+	t.Parallel()
+
+	// enough input for the Parser to stop keeping track of the beginning
+	line := `{"a": "` + strings.Repeat("x", 100) + "\"}\n"
+	input := strings.Repeat(line, 50000) + "{\"a\": }\n"
+
+	_, err := jsonstruct.NewParser(strings.NewReader(input), slog.Default()).Start()
+
+	parseErr := &jsonstruct.ParseError{}
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 50001, parseErr.Line)
+	assert.Equal(t, 7, parseErr.Column)
+	assert.Equal(t, int64(len(input)-2), parseErr.Offset)
+	assert.Equal(t, "line 50001, column 7 (at $.a): failed to parse object: failed to parse value: failed to get next "+
+		"token: missing value after object key", parseErr.Error())
+}
+
+func TestParseErrorLenientSyntax(t *testing.T) {
+	t.Parallel()
+
+	parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(`{a: 0xZZ}`), slog.Default(),
+		&jsonstruct.ParserOptions{Lenient: true})
+	assert.Nil(t, err)
+
+	_, err = parser.Start()
+	assert.True(t, errors.Is(err, jsonstruct.ErrLenientSyntax))
+}
@@ -17,6 +17,31 @@ type Field struct {
 	rawValue     any
 	optional     bool
 	isJSONRaw    bool
+	nullable     bool
+	doc          string
+	// tagKey is the key of the struct tag holding originalName, e.g. "yaml" for fields parsed from YAML. Defaults to
+	// "json".
+	tagKey string
+	// formatTypes maps the formats of string values to their Go types, as set by the Formatter. Defaults to
+	// defaultFormatGoTypes.
+	formatTypes map[StringFormat]string
+	// strings are the distinct values of a string field merged from several samples, used to detect enums.
+	strings *stringValues
+	// enum is the type declared for the values of the field, if they were detected as an enum.
+	enum *enumType
+	// typeName is the name of the type of the struct this field holds, if any. Defaults to goName.
+	typeName string
+	// goType is the type of the field set by an Override, which replaces the inferred one.
+	goType string
+	// tag is the struct tag of the field set by an Override, which replaces the generated one.
+	tag string
+	// element caches the merged elements of a slice or values of a map so that the structs nested within them are only
+	// created once. It's a pointer so that it can be filled in by methods with value receivers.
+	element *elementCache
+}
+
+type elementCache struct {
+	field *Field
 }
 
 func NewField() *Field {
@@ -32,6 +57,18 @@ func (f *Field) SetName(originalName string) *Field {
 
 func (f *Field) SetValue(value any) *Field {
 	f.rawValue = value
+	f.element = &elementCache{}
+
+	return f
+}
+
+// SetTypeName sets the name of the type of the struct this field holds, which defaults to the field's name.
+func (f *Field) SetTypeName(typeName string) *Field {
+	f.typeName = typeName
+
+	if js := f.namedStruct(); js != nil {
+		js.name = typeName
+	}
 
 	return f
 }
@@ -48,6 +85,47 @@ func (f *Field) SetJSONRaw() *Field {
 	return f
 }
 
+// setTagKey sets the key of the struct tag holding the field's original name.
+func (f *Field) setTagKey(tagKey string) *Field {
+	f.tagKey = tagKey
+
+	return f
+}
+
+// SetDoc sets the doc comment rendered above the field, e.g. from a comment next to its key in lenient mode.
+func (f *Field) SetDoc(doc string) *Field {
+	f.doc = doc
+
+	return f
+}
+
+// SetNullable marks the field as having been null in some samples, making its type a pointer.
+func (f *Field) SetNullable() *Field {
+	f.nullable = true
+
+	return f
+}
+
+// setMergedValue sets the value of f to the merge of values. Nulls make f nullable rather than conflicting with the
+// other values, and f is typed as *json.RawMessage if the remaining values are incompatible.
+func (f *Field) setMergedValue(values []any) *Field {
+	nonNull := withoutNulls(values)
+	if len(nonNull) == 0 {
+		return f.SetValue(nil)
+	}
+
+	merged, ok := mergeValues(nonNull)
+	if !ok {
+		return f.SetJSONRaw()
+	}
+
+	if len(nonNull) != len(values) {
+		f.SetNullable()
+	}
+
+	return f.SetValue(merged)
+}
+
 // Name returns the name of this field as it will be rendered in the final struct.
 func (f Field) Name() string {
 	return f.goName
@@ -57,26 +135,96 @@ func (f Field) OriginalName() string {
 	return f.originalName
 }
 
-// Tag returns the JSON tag as it will be rendered in the final struct.
-func (f Field) Tag() string {
-	if f.originalName == f.Name() {
-		return ""
+// TypeName returns the name of the type of the struct this field holds, if any.
+func (f Field) TypeName() string {
+	if js := f.namedStruct(); js != nil {
+		return js.name
 	}
 
-	if f.optional {
-		return fmt.Sprintf("`json:\"%s,omitempty\"`", f.originalName)
+	if f.typeName != "" {
+		return f.typeName
 	}
 
-	return fmt.Sprintf("`json:\"%s\"`", f.originalName)
+	return f.goName
+}
+
+// namedStruct returns the struct held by f if it keeps its own name, or nil otherwise.
+func (f Field) namedStruct() *JSONStruct {
+	js, ok := f.rawValue.(*JSONStruct)
+	if !ok && (f.IsSlice() || f.IsMap()) {
+		js = f.GetStruct()
+	}
+
+	if js != nil && js.named {
+		return js
+	}
+
+	return nil
+}
+
+// Doc returns the doc comment rendered above the field, if any.
+func (f Field) Doc() string {
+	return f.doc
+}
+
+// Tag returns the struct tag as it will be rendered in the final struct with the default FormatterOptions, for the
+// format the field was parsed from.
+func (f Field) Tag() string {
+	return (&FormatterOptions{}).fieldTag(&f)
+}
+
+// Format returns the StringFormat recognized in the field's string values, or an empty string if they don't share one
+// or aren't strings. The formats of the elements of slices and values of maps are found on SliceElementField and
+// MapValueField.
+func (f Field) Format() StringFormat {
+	return stringFormat(f.rawValue)
+}
+
+// Nullable returns true if the field was null in some, but not all, of the samples it was merged from.
+func (f Field) Nullable() bool {
+	return f.nullable
+}
+
+// IsNull returns true if the field was null in every sample.
+func (f Field) IsNull() bool {
+	return f.rawValue == nil && !f.isJSONRaw
 }
 
 // Type returns the type of the field as it will be rendered in the final struct.
 func (f Field) Type() string {
+	if f.goType != "" {
+		return f.goType
+	}
+
+	fieldType := f.baseType()
+
+	// slices, maps, pointers, and interfaces can already hold null
+	if f.nullable && !strings.HasPrefix(fieldType, "*") && !strings.HasPrefix(fieldType, "[]") &&
+		!strings.HasPrefix(fieldType, "map[") && fieldType != "any" {
+		return "*" + fieldType
+	}
+
+	return fieldType
+}
+
+// baseType returns the type of the field without accounting for nulls.
+func (f Field) baseType() string {
+	if f.goType != "" {
+		return f.goType
+	}
+
 	if f.rawValue == nil || f.isJSONRaw {
-		return jsonRawMessage
+		return f.rawType()
 	}
 
-	switch f.rawValue.(type) {
+	switch val := f.rawValue.(type) {
+	case formattedString:
+		// encoding/json can't omit empty struct types, so optional ones need to be pointers
+		if f.optional && val.needsPointer(f.formatTypes) {
+			return "*" + val.goType(f.formatTypes)
+		}
+
+		return val.goType(f.formatTypes)
 	case int64:
 		return "int64"
 	case *big.Int:
@@ -86,9 +234,15 @@ func (f Field) Type() string {
 	case *big.Float:
 		return "*big.Float"
 	case string:
+		if f.enum != nil {
+			return f.enum.name
+		}
+
 		return "string"
 	case bool:
 		return "bool"
+	case anyValue:
+		return f.rawType()
 	}
 
 	if f.IsSlice() {
@@ -96,73 +250,70 @@ func (f Field) Type() string {
 	}
 
 	if f.IsStruct() {
-		return fmt.Sprintf("*%s", f.goName)
+		return fmt.Sprintf("*%s", f.TypeName())
+	}
+
+	if f.IsMap() {
+		return fmt.Sprintf("map[string]%s", f.MapValueField().Type())
 	}
 
 	return "any"
 }
 
+// rawType returns the type of fields that can hold any value: *json.RawMessage, or any for fields parsed from YAML or
+// TOML, since their decoders can't decode into a json.RawMessage.
+func (f Field) rawType() string {
+	if f.tagKey != "" {
+		return "any"
+	}
+
+	return jsonRawMessage
+}
+
 // SliceType returns the type of the slice this field represents.
 func (f Field) SliceType() string {
-	// rawVal := reflect.ValueOf(f.rawValue)
-	rawType := reflect.TypeOf(f.rawValue)
-
-	if rawType.Kind() != reflect.Slice {
+	if !f.IsSlice() {
 		return ""
 	}
 
-	// we have a field that represents a struct, whose slice type is its name
-	if f.IsStructSlice() {
-		return fmt.Sprintf("[]*%s", f.Name())
-	}
-
-	return getSliceType(f.rawValue)
+	return fmt.Sprintf("[]%s", f.SliceElementField().Type())
 }
 
-func getSliceType(input any) string {
-	var (
-		rawVal = reflect.ValueOf(input)
-		// rawType   = reflect.TypeOf(input)
-		sliceType string
-	)
-
-	// walk the array's elements and figure out if they're all typed the same
-	for i := 0; i < rawVal.Len(); i++ {
-		var itemType string
-
-		idxVal := rawVal.Index(i)
-		kind := idxVal.Type().Kind()
-
-		if kind == reflect.Pointer || kind == reflect.Interface {
-			idxVal = idxVal.Elem()
-		}
-
-		if !idxVal.IsValid() {
-			sliceType = jsonRawMessage
-
-			break
-		}
+// SliceElementField returns a Field with the same name as f representing the merged elements of the slice in RawValue.
+// If the elements can't be represented by a single type, the Field will be typed as *json.RawMessage (or any for YAML /
+// TOML input), and if some of them are null, it will be nullable. Returns nil if f is not a slice.
+func (f Field) SliceElementField() *Field {
+	if !f.IsSlice() {
+		return nil
+	}
 
-		idxType := idxVal.Type()
-		kind = idxType.Kind()
+	return f.elementField(func() []any { return anySlice(f.rawValue) })
+}
 
-		if kind == reflect.Slice {
-			itemType = getSliceType(idxVal.Interface())
-		} else {
-			itemType = idxType.String()
-		}
+// elementField returns a Field named after f holding the merge of the values returned by getValues, which are only
+// computed the first time it's called.
+func (f Field) elementField(getValues func() []any) *Field {
+	var merged *Field
 
-		// we have encountered multiple types for this field, have to accept anything
-		if sliceType != "" && itemType != sliceType {
-			sliceType = jsonRawMessage
+	if f.element != nil && f.element.field != nil {
+		merged = f.element.field
+	} else {
+		merged = NewField().setMergedValue(getValues())
+		merged.rawValue = plainString(merged.rawValue)
 
-			break
+		if f.element != nil {
+			f.element.field = merged
 		}
-
-		sliceType = itemType
 	}
 
-	return fmt.Sprintf("[]%s", sliceType)
+	result := *merged
+	result.SetName(f.originalName)
+	result.typeName = f.typeName
+	// the elements are decoded from the same format
+	result.tagKey = f.tagKey
+	result.formatTypes = f.formatTypes
+
+	return &result
 }
 
 // Value returns the string version of RawValue.
@@ -194,7 +345,7 @@ func (f Field) SimpleSliceValues() []string {
 	rawVal := reflect.ValueOf(f.rawValue)
 
 	switch f.SliceType() {
-	case "[]int64", "[]float64", "[]bool", "[]string":
+	case "[]int64", "[]*big.Int", "[]float64", "[]*big.Float", "[]bool", "[]string", "[]time.Time":
 		for i := 0; i < rawVal.Len(); i++ {
 			idxVal := rawVal.Index(i)
 			kind := idxVal.Type().Kind()
@@ -230,23 +381,32 @@ func (f Field) Comment() string {
 	return comment
 }
 
-// IsStruct returns true if RawValue is either a struct or a pointer to a struct.
+// IsStruct returns true if RawValue is a *JSONStruct.
 func (f Field) IsStruct() bool {
-	if f.rawValue == nil {
-		return false
-	}
+	_, ok := f.rawValue.(*JSONStruct)
 
-	typ := reflect.TypeOf(f.rawValue)
-	kind := typ.Kind()
+	return ok
+}
 
-	if kind == reflect.Ptr {
-		kind = typ.Elem().Kind()
+// HasStruct returns true if f needs a struct type: it is a struct, a slice of structs, or a map whose values are
+// structs, and its type wasn't overridden.
+func (f Field) HasStruct() bool {
+	switch {
+	case f.goType != "":
+		return false
+	case f.IsStruct(), f.IsStructSlice():
+		return true
+	case f.IsSlice():
+		return f.SliceElementField().HasStruct()
+	case f.IsMap():
+		return f.MapValueField().HasStruct()
 	}
 
-	return kind == reflect.Struct
+	return false
 }
 
-// GetStruct gets a the JSONStruct in RawValue if f is a struct or slice of struct, otherwise returns nil.
+// GetStruct gets a the JSONStruct in RawValue if f is a struct, slice of struct, or map of struct, otherwise returns
+// nil.
 func (f Field) GetStruct() *JSONStruct {
 	switch {
 	case f.IsStruct():
@@ -255,21 +415,24 @@ func (f Field) GetStruct() *JSONStruct {
 			return nil
 		}
 
-		return js.SetName(f.Name())
+		return js.SetName(f.TypeName())
 	case f.IsStructSlice():
 		return f.GetSliceStruct()
+	case f.IsSlice():
+		return f.SliceElementField().GetStruct()
+	case f.IsMap():
+		return f.MapValueField().GetStruct()
 	default:
 		return nil
 	}
 }
 
 func (f Field) GetSliceStruct() *JSONStruct {
-	anySlice, ok := f.rawValue.([]any)
-	if !ok {
+	if !f.IsStructSlice() {
 		return nil
 	}
 
-	return getSliceStruct(anySlice).SetName(f.Name())
+	return f.SliceElementField().GetStruct()
 }
 
 func getSliceStruct(input []any) *JSONStruct {
@@ -280,11 +443,17 @@ func getSliceStruct(input []any) *JSONStruct {
 		return nil
 	}
 
-	// foundFields contains the first instance of a field, while fieldCounts reports the number of structs containing it
+	// a single struct is already merged, and copying it would break structs that refer to themselves
+	if len(jStructs) == 1 {
+		return jStructs[0]
+	}
+
+	// foundFields contains the first instance of a field, fieldValues contains its values in every struct containing it
 	// have to use synced slices here to avoid the reordering that would occur with a map
 	foundFields := []*Field{}
-	fieldTypes := []string{}
-	fieldCounts := []int{}
+	fieldValues := [][]any{}
+	fieldInstances := [][]*Field{}
+	fieldDocs := []string{}
 
 	// have a slice of structs, each of which may or may not contain the full set of fields - walk each and find the
 	// fields that don't reoccur
@@ -294,8 +463,6 @@ func getSliceStruct(input []any) *JSONStruct {
 
 			for i, foundField := range foundFields {
 				if field.OriginalName() == foundField.OriginalName() {
-					fieldCounts[i]++
-
 					foundIndex = i
 
 					break
@@ -304,30 +471,71 @@ func getSliceStruct(input []any) *JSONStruct {
 
 			if foundIndex == -1 {
 				foundFields = append(foundFields, field)
-				fieldTypes = append(fieldTypes, field.Type())
-				fieldCounts = append(fieldCounts, 1)
-			} else if field.Type() != fieldTypes[foundIndex] {
-				foundFields[foundIndex].SetJSONRaw()
+				fieldValues = append(fieldValues, []any{field.rawValue})
+				fieldInstances = append(fieldInstances, []*Field{field})
+				fieldDocs = append(fieldDocs, field.Doc())
+			} else {
+				fieldValues[foundIndex] = append(fieldValues[foundIndex], field.rawValue)
+				fieldInstances[foundIndex] = append(fieldInstances[foundIndex], field)
+
+				// use the first doc comment found for the field
+				if fieldDocs[foundIndex] == "" {
+					fieldDocs[foundIndex] = field.Doc()
+				}
 			}
 		}
 	}
 
-	for i := 0; i < len(foundFields); i++ {
-		if fieldCounts[i] != len(jStructs) {
-			foundFields[i].SetOptional()
+	for i, foundField := range foundFields {
+		// copy the field so the structs that were passed in aren't modified; if we have encountered multiple types for
+		// this field, it has to accept anything
+		field := *foundField
+		field.setMergedValue(fieldValues[i]).SetDoc(fieldDocs[i])
+		field.strings = mergeStringValues(fieldInstances[i])
+
+		if len(fieldValues[i]) != len(jStructs) {
+			field.SetOptional()
 		}
 
-		result.AddFields(foundFields[i])
+		result.AddFields(&field)
 	}
 
 	return result
 }
 
+// IsMap returns true if RawValue is an object that was detected as a map rather than a struct.
+func (f Field) IsMap() bool {
+	_, ok := f.rawValue.(*jsonMap)
+
+	return ok
+}
+
+// MapValueField returns a Field with the same name as f representing the merged values of the map in RawValue. If the
+// values can't be represented by a single type, the Field will be typed as *json.RawMessage (or any for YAML / TOML
+// input), and if some of them are null, it will be nullable. Returns nil if f is not a map.
+func (f Field) MapValueField() *Field {
+	jMap, ok := f.rawValue.(*jsonMap)
+	if !ok {
+		return nil
+	}
+
+	return f.elementField(jMap.values)
+}
+
+// isStringNumber returns true if the field holds a number encoded as a string.
+func (f Field) isStringNumber() bool {
+	str, ok := f.rawValue.(formattedString)
+
+	return ok && str.isNumeric() && str.goType(f.formatTypes) != "string"
+}
+
 // IsSlice returns true if RawValue is of kind slice.
 func (f Field) IsSlice() bool {
-	kind := reflect.TypeOf(f.rawValue).Kind()
+	if f.rawValue == nil {
+		return false
+	}
 
-	return kind == reflect.Slice
+	return reflect.TypeOf(f.rawValue).Kind() == reflect.Slice
 }
 
 func (f Field) IsStructSlice() bool {
@@ -336,12 +544,15 @@ func (f Field) IsStructSlice() bool {
 
 func isStructSlice(input any) bool {
 	anySlice, ok := input.([]any)
-	if !ok {
+	if !ok || len(anySlice) == 0 {
 		return false
 	}
 
-	if _, err := anySliceToJSONStructs(anySlice); err != nil {
-		return false
+	// checked directly rather than with anySliceToJSONStructs, since this is called for every field
+	for _, item := range anySlice {
+		if _, ok := item.(*JSONStruct); !ok {
+			return false
+		}
 	}
 
 	return true
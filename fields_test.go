@@ -44,6 +44,17 @@ func TestFieldType(t *testing.T) {
 		{"structs", []any{jsonstruct.New()}, "[]*Structs"},
 		{"nested_int64_slices", []any{[]int64{1, 2, 3}, []int64{4, 5, 6}}, "[][]int64"},
 		{"nested_float64_slices", []any{[]float64{1, 2, 3}, []float64{4, 5, 6}}, "[][]float64"},
+		{"empty_slice", []any{}, "[]*json.RawMessage"},
+		{"int_float_slice", []any{int64(1), 2.5}, "[]float64"},
+		{"int_big_int_slice", []any{int64(1), bigInt}, "[]*big.Int"},
+		{"float_big_int_slice", []any{1.5, bigInt}, "[]*big.Float"},
+		{"int_big_float_slice", []any{int64(1), bigFloat}, "[]*big.Float"},
+		{"number_string_slice", []any{int64(1), "1"}, "[]*json.RawMessage"},
+		{"nested_int_float_slices", []any{[]any{int64(1)}, []any{2.5}}, "[][]float64"},
+		{"nullable_int_slice", []any{int64(1), nil}, "[]*int64"},
+		{"nullable_string_slice", []any{nil, "1"}, "[]*string"},
+		{"nullable_struct_slice", []any{jsonstruct.New(), nil}, "[]*NullableStructSlice"},
+		{"nullable_nested_slice", []any{[]any{int64(1)}, nil}, "[][]int64"},
 		{
 			name:   "deeply_nested_int64_slices",
 			input:  []any{[]any{[]any{[]any{[]int64{1, 2, 3}}}}},
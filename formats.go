@@ -0,0 +1,399 @@
+package jsonstruct
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StringFormat identifies a well-known format recognized in JSON string values.
+type StringFormat string
+
+const (
+	// FormatDateTime is an RFC 3339 timestamp, with or without fractional seconds.
+	FormatDateTime StringFormat = "date-time"
+	// FormatDate is a date without a time, e.g. "2024-01-02".
+	FormatDate StringFormat = "date"
+	// FormatInteger is an integer encoded as a string, e.g. "1234567890123".
+	FormatInteger StringFormat = "integer"
+	// FormatNumber is a number with a fraction or an exponent encoded as a string, e.g. "19.99".
+	FormatNumber StringFormat = "number"
+	// FormatUUID is a UUID, e.g. "123e4567-e89b-12d3-a456-426614174000".
+	FormatUUID StringFormat = "uuid"
+	// FormatURI is an absolute URL with a host, e.g. "https://example.com/a".
+	FormatURI StringFormat = "uri"
+	// FormatIPv4 is an IPv4 address, e.g. "10.0.0.1".
+	FormatIPv4 StringFormat = "ipv4"
+	// FormatIPv6 is an IPv6 address, e.g. "2001:db8::1".
+	FormatIPv6 StringFormat = "ipv6"
+	// FormatIP is an IPv4 or IPv6 address, used when a field holds both.
+	FormatIP StringFormat = "ip"
+	// FormatEmail is an email address, e.g. "user@example.com".
+	FormatEmail StringFormat = "email"
+	// FormatHostname is a fully qualified domain name, e.g. "api.example.com".
+	FormatHostname StringFormat = "hostname"
+	// FormatDuration is a Go duration, e.g. "1h30m".
+	FormatDuration StringFormat = "duration"
+	// FormatBase64 is base64-encoded binary data, named after the OpenAPI format.
+	FormatBase64 StringFormat = "byte"
+)
+
+// dateType is the type declared for dates, since encoding/json can only unmarshal RFC 3339 timestamps into a time.Time.
+const dateType = "Date"
+
+// dateDeclaration declares dateType, which embeds a time.Time and reads and writes dates like "2006-01-02" in JSON, as
+// well as in YAML and TOML through its text methods.
+const dateDeclaration = `
+// Date is a time.Time written as a date without a time, e.g. "2006-01-02".
+type Date struct {
+	time.Time
+}
+
+// MarshalJSON formats the date as "2006-01-02".
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(time.DateOnly))
+}
+
+// UnmarshalJSON parses a date like "2006-01-02", leaving the date unchanged for null.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	return d.UnmarshalText([]byte(text))
+}
+
+// MarshalText formats the date as "2006-01-02".
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.Format(time.DateOnly)), nil
+}
+
+// UnmarshalText parses a date like "2006-01-02". TOML decoders pass local dates as timestamps at midnight, which are
+// accepted too.
+func (d *Date) UnmarshalText(data []byte) error {
+	parsed, err := time.Parse(time.DateOnly, string(data))
+	if err == nil {
+		d.Time = parsed
+
+		return nil
+	}
+
+	if d.Time.UnmarshalText(data) != nil {
+		return err
+	}
+
+	d.Time = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+
+	return nil
+}
+`
+
+// defaultFormatGoTypes maps each StringFormat to the Go type used for fields holding values of that format, unless
+// FormatterOptions.FormatGoTypes sets another one. Dates get the Date type declared by the Formatter.
+//
+//nolint:gochecknoglobals
+var defaultFormatGoTypes = map[StringFormat]string{
+	FormatDateTime: "time.Time",
+	FormatDate:     dateType,
+	FormatInteger:  "int64",
+	FormatNumber:   "float64",
+	FormatIPv4:     "netip.Addr",
+	FormatIPv6:     "netip.Addr",
+	FormatIP:       "netip.Addr",
+	FormatBase64:   "[]byte",
+}
+
+// formatValidateTags maps each StringFormat to the go-playground/validator rule added to the validate tag of string
+// fields holding values of that format, e.g. `validate:"required,uuid"`. Formats with another Go type aren't validated.
+//
+//nolint:gochecknoglobals
+var formatValidateTags = map[StringFormat]string{
+	FormatUUID:     "uuid",
+	FormatURI:      "url",
+	FormatIPv4:     "ipv4",
+	FormatIPv6:     "ipv6",
+	FormatIP:       "ip",
+	FormatEmail:    "email",
+	FormatHostname: "fqdn",
+	FormatBase64:   "base64",
+}
+
+// formatGoTypes returns defaultFormatGoTypes with the types in goTypes, along with the import paths of the packages
+// they name, keyed by package name. The types can name their package by import path, e.g. "*net/url.URL".
+func formatGoTypes(goTypes map[StringFormat]string) (map[StringFormat]string, map[string]string, error) {
+	types := make(map[StringFormat]string, len(defaultFormatGoTypes)+len(goTypes))
+	imports := map[string]string{}
+
+	for format, goType := range defaultFormatGoTypes {
+		types[format] = goType
+	}
+
+	for format, goType := range goTypes {
+		typ, qualifier, importPath, err := qualifyType(goType)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid type for format %q: %w", format, err)
+		}
+
+		if importPath != "" {
+			existing, ok := imports[qualifier]
+			if !ok {
+				existing, ok = knownImports[qualifier]
+			}
+
+			if ok && existing != importPath {
+				return nil, nil, fmt.Errorf("package name %q of %q is already used by %q", qualifier, importPath, existing)
+			}
+
+			imports[qualifier] = importPath
+		}
+
+		types[format] = typ
+	}
+
+	return types, imports, nil
+}
+
+// applyFormatTypes sets the Go types of the formats on the fields of inputs and of the structs nested within them, and
+// records the import paths of the packages those types and NullType name, as well as whether the Date type has to be
+// declared.
+func (f *Formatter) applyFormatTypes(inputs []*JSONStruct) error {
+	f.declaresDate = false
+
+	for qualifier, importPath := range f.typeImports {
+		if existing, ok := f.imports[qualifier]; ok && existing != importPath {
+			return fmt.Errorf("package name %q of %q is already used by %q", qualifier, importPath, existing)
+		}
+
+		f.imports[qualifier] = importPath
+	}
+
+	visited := map[*JSONStruct]bool{}
+
+	var visit func(js *JSONStruct)
+
+	visit = func(js *JSONStruct) {
+		if visited[js] {
+			return
+		}
+
+		visited[js] = true
+
+		fields := js.fields
+		if js.value != nil {
+			fields = Fields{js.value}
+		}
+
+		for _, field := range fields {
+			field.formatTypes = f.formatTypes
+
+			if f.formatTypes[FormatDate] == dateType && holdsFormat(field, FormatDate) {
+				// a package that already declares Date, e.g. in another generated file, doesn't need it twice
+				f.declaresDate = !slices.Contains(f.ReservedNames, dateType)
+			}
+
+			if field.HasStruct() {
+				visit(field.GetStruct())
+			}
+		}
+	}
+
+	for _, input := range inputs {
+		visit(input)
+	}
+
+	return nil
+}
+
+// holdsFormat returns true if field holds strings of format, or slices or maps of them, and wasn't given another type.
+func holdsFormat(field *Field, format StringFormat) bool {
+	switch {
+	case field.goType != "":
+		return false
+	case field.Format() == format:
+		return true
+	case field.IsSlice():
+		return holdsFormat(field.SliceElementField(), format)
+	case field.IsMap():
+		return holdsFormat(field.MapValueField(), format)
+	}
+
+	return false
+}
+
+// qualifyType returns goType with the import path of its package, if it names one, replaced by the package name, as
+// well as that package name and import path, e.g. "*url.URL", "url", and "net/url" for "*net/url.URL". The package name
+// leaves out major versions, e.g. "null" for both "gopkg.in/guregu/null.v4" and "github.com/volatiletech/null/v8".
+func qualifyType(goType string) (typ, qualifier, importPath string, err error) {
+	// e.g. "*" or "[]" before the type itself
+	typeStart := strings.LastIndexAny(goType, "*]") + 1
+	prefix, name := goType[:typeStart], goType[typeStart:]
+
+	if slashIndex := strings.LastIndexByte(name, '/'); slashIndex != -1 {
+		dotIndex := strings.LastIndexByte(name, '.')
+		if dotIndex < slashIndex {
+			return "", "", "", fmt.Errorf("invalid type %q, expecting IMPORT/PATH.Name", goType)
+		}
+
+		importPath = name[:dotIndex]
+		qualifier = importQualifier(importPath)
+		name = qualifier + name[dotIndex:]
+	}
+
+	if _, err := parser.ParseExpr(prefix + name); err != nil {
+		return "", "", "", fmt.Errorf("invalid type %q: %w", goType, err)
+	}
+
+	return prefix + name, qualifier, importPath, nil
+}
+
+// importQualifier returns the package name conventionally used for importPath: its last element, without a major
+// version.
+func importQualifier(importPath string) string {
+	qualifier := path.Base(importPath)
+	if _, err := strconv.Atoi(strings.TrimPrefix(qualifier, "v")); err == nil && qualifier[0] == 'v' &&
+		path.Dir(importPath) != "." {
+		qualifier = path.Base(path.Dir(importPath))
+	}
+
+	if dotIndex := strings.IndexByte(qualifier, '.'); dotIndex > 0 {
+		qualifier = qualifier[:dotIndex]
+	}
+
+	return qualifier
+}
+
+// formattedString is a JSON string value that was recognized as having a StringFormat.
+type formattedString struct {
+	value  string
+	format StringFormat
+}
+
+// goType returns the Go type used to represent the string, looked up in goTypes, or defaultFormatGoTypes if it's nil.
+func (s formattedString) goType(goTypes map[StringFormat]string) string {
+	if goTypes == nil {
+		goTypes = defaultFormatGoTypes
+	}
+
+	if goType, ok := goTypes[s.format]; ok {
+		return goType
+	}
+
+	return "string"
+}
+
+// needsPointer returns true if the Go type of the string is a struct, like time.Time or Date, which encoding/json can't
+// omit when empty.
+func (s formattedString) needsPointer(goTypes map[StringFormat]string) bool {
+	goType := s.goType(goTypes)
+	if goType == dateType {
+		return true
+	}
+
+	return strings.Contains(goType, ".") && !strings.HasPrefix(goType, "*") && !strings.HasPrefix(goType, "[]")
+}
+
+// validateRule returns the validator rule for the format of the string, or an empty string if it has none or isn't
+// typed as a string.
+func (s formattedString) validateRule(goTypes map[StringFormat]string) string {
+	if s.goType(goTypes) != "string" {
+		return ""
+	}
+
+	return formatValidateTags[s.format]
+}
+
+// isNumeric returns true if the string is a number, which encoding/json decodes with the ",string" option unless it's
+// typed as a string.
+func (s formattedString) isNumeric() bool {
+	return s.format == FormatInteger || s.format == FormatNumber
+}
+
+// timeFormat returns the StringFormat of input if it is an RFC 3339 timestamp or a date, or an empty string otherwise.
+func timeFormat(input string) StringFormat {
+	// quick check to skip parsing strings that can't be dates: must start with "YYYY-"
+	if len(input) < len(time.DateOnly) || input[4] != '-' {
+		return ""
+	}
+
+	if len(input) == len(time.DateOnly) {
+		if _, err := time.Parse(time.DateOnly, input); err == nil {
+			return FormatDate
+		}
+
+		return ""
+	}
+
+	for _, layout := range []string{time.RFC3339, time.RFC3339Nano} {
+		if _, err := time.Parse(layout, input); err == nil {
+			return FormatDateTime
+		}
+	}
+
+	return ""
+}
+
+// numberFormat returns the StringFormat of input if it is a JSON number that fits in an int64 or a float64, or an empty
+// string otherwise. Numbers with leading zeros, signs, or spaces aren't JSON numbers, so e.g. zip codes stay strings.
+func numberFormat(input string) StringFormat {
+	// quick check to skip strings that can't be numbers, including the other JSON literals
+	if input == "" || strings.IndexByte("-0123456789", input[0]) == -1 || !isNumber(rune(input[len(input)-1])) ||
+		!json.Valid([]byte(input)) {
+		return ""
+	}
+
+	if !strings.ContainsAny(input, ".eE") {
+		if _, err := strconv.ParseInt(input, 10, 64); err != nil {
+			return ""
+		}
+
+		return FormatInteger
+	}
+
+	if _, err := strconv.ParseFloat(input, 64); errors.Is(err, strconv.ErrRange) {
+		return ""
+	}
+
+	return FormatNumber
+}
+
+// plainString returns the raw string held by value if it is a number encoded as a string, or value otherwise. The
+// ",string" option only applies to struct fields, so the elements of slices and maps and top-level values stay strings.
+func plainString(value any) any {
+	if str, ok := value.(formattedString); ok && str.isNumeric() {
+		return str.value
+	}
+
+	return value
+}
+
+// stringValue returns the raw string held by input if it is a string or a formattedString.
+func stringValue(input any) (string, bool) {
+	switch val := input.(type) {
+	case string:
+		return val, true
+	case formattedString:
+		return val.value, true
+	}
+
+	return "", false
+}
+
+// stringFormat returns the StringFormat of input if it is a formattedString, or an empty string otherwise.
+func stringFormat(input any) StringFormat {
+	if str, ok := input.(formattedString); ok {
+		return str.format
+	}
+
+	return ""
+}
@@ -1,12 +1,17 @@
 package jsonstruct
 
 import (
+	"errors"
 	"fmt"
+	"go/token"
+	"slices"
 	"strings"
 
 	"mvdan.cc/gofumpt/format"
 )
 
+var ErrRecursiveInline = errors.New("structs that refer to themselves can't be inlined")
+
 // FormatterOptions defines how the Formatter will produce its output.
 type FormatterOptions struct {
 	// SortFields returns fields in alphabetically sorted order.
@@ -18,16 +23,197 @@ type FormatterOptions struct {
 
 	// InlineStructs causes objects within the main object to be rendered inline rather than getting their own types.
 	InlineStructs bool
+
+	// PackageName switches the Formatter to "file mode": if set, the output is a complete Go source file with this
+	// package clause and the imports required by the generated types.
+	PackageName string
+
+	// GeneratedHeader adds a "Code generated ... DO NOT EDIT." comment to the top of the output in file mode.
+	GeneratedHeader bool
+
+	// NullType is the Go type used for fields that are null in every sample. Defaults to *json.RawMessage. It can name
+	// its package by import path, e.g. "gopkg.in/guregu/null.v4.String".
+	NullType string
+
+	// DedupeStructs collapses nested structs with identical shapes (e.g. "billing_address" and "shipping_address") into
+	// a single shared type. It has no effect with InlineStructs.
+	DedupeStructs bool
+
+	// DetectRecursion makes nested structs whose fields are a subset of one of the structs containing them (e.g. the
+	// "replies" of a comment) refer to that struct, as in "Replies []*Comment". It has no effect with InlineStructs.
+	DetectRecursion bool
+
+	// DedupeNaming decides which name is used for shared types. Defaults to NamingFirstSeen.
+	DedupeNaming NamingPolicy
+
+	// TypeNames provides names for shared types: if any of the structs collapsed into a shared type would have been
+	// named after one of the keys, the shared type is named after its value instead.
+	TypeNames map[string]string
+
+	// CollisionNaming decides how nested structs are renamed when they would have the same name as a struct with a
+	// different shape (e.g. two unrelated "items" objects). Defaults to CollisionParent.
+	CollisionNaming CollisionPolicy
+
+	// ReservedNames are declared elsewhere in the package, e.g. by other generated files. Nested structs and enums are
+	// renamed to avoid them as they are for CollisionNaming, and it's an error for a top-level struct to use one. If it
+	// holds "Date", the Date type of date fields is used without being declared again.
+	ReservedNames []string
+
+	// Tags are struct tags rendered for every field after its json tag (or yaml / toml tag for fields parsed from those
+	// formats), e.g. for bson, db, or mapstructure. A tag with the same key as a field's own tag sets the naming and
+	// omitempty of that tag rather than adding another one, e.g. "json" with TagSnake for JSON input.
+	Tags []TagOptions
+
+	// AlwaysTag renders the json tag (or yaml / toml tag) even for fields whose names match their keys.
+	AlwaysTag bool
+
+	// DetectEnums declares a named string type with a constant for each value (e.g. "type OrderStatus string") for
+	// string fields that take a small set of values across the samples of a slice or NDJSON stream.
+	DetectEnums bool
+
+	// EnumMaxValues is the largest number of distinct values a field can take to be declared as an enum. Defaults to
+	// DefaultEnumMaxValues.
+	EnumMaxValues int
+
+	// EnumMethods adds a Valid method and an UnmarshalJSON method rejecting unknown values to every enum type.
+	EnumMethods bool
+
+	// Overrides change the types, names, and tags of the fields they select, or leave them out. They are applied before
+	// Overrides set in the ParserOptions of the structs being formatted. They don't apply to JSON Schemas.
+	Overrides []Override
+
+	// FormatGoTypes sets the Go types of fields holding strings of the given formats in place of the default ones, e.g.
+	// time.Time for "date-time". A type can name its package by import path, e.g. "github.com/google/uuid.UUID" or
+	// "*net/url.URL", to have it imported. Formats can be recognized by the Classifiers of the ParserOptions.
+	FormatGoTypes map[StringFormat]string
+}
+
+// GeneratedHeader is the comment added to the top of generated files when FormatterOptions.GeneratedHeader is set.
+const GeneratedHeader = "// Code generated by jsonstruct. DO NOT EDIT."
+
+// nameStructs gives the structs nested in inputs their final names, unless they are inlined: the name of an ancestor
+// for recursive structs if DetectRecursion is set, a single shared name for identical structs if DedupeStructs is set,
+// and a unique name for each of the others.
+func (f *FormatterOptions) nameStructs(inputs []*JSONStruct) {
+	if f.InlineStructs {
+		return
+	}
+
+	f.detectRecursion(inputs)
+
+	if f.DedupeStructs {
+		f.dedupeStructs(inputs)
+	}
+
+	f.resolveCollisions(inputs)
 }
 
 // OK ensures that the options passed in are valid.
 func (f *FormatterOptions) OK() error {
+	if f.PackageName != "" && !token.IsIdentifier(f.PackageName) {
+		return fmt.Errorf("invalid package name %q", f.PackageName)
+	}
+
+	if f.GeneratedHeader && f.PackageName == "" {
+		return fmt.Errorf("a package name is required to add the generated header")
+	}
+
+	switch f.DedupeNaming {
+	case "", NamingFirstSeen, NamingShortest:
+	default:
+		return fmt.Errorf("invalid naming policy %q", f.DedupeNaming)
+	}
+
+	switch f.CollisionNaming {
+	case "", CollisionParent, CollisionNumber:
+	default:
+		return fmt.Errorf("invalid collision policy %q", f.CollisionNaming)
+	}
+
+	for name, typeName := range f.TypeNames {
+		if !token.IsIdentifier(typeName) {
+			return fmt.Errorf("invalid type name %q for %q", typeName, name)
+		}
+	}
+
+	if f.NullType != "" {
+		if _, _, _, err := qualifyType(f.NullType); err != nil {
+			return fmt.Errorf("invalid null type: %w", err)
+		}
+	}
+
+	if f.EnumMaxValues < 0 {
+		return fmt.Errorf("invalid maximum number of enum values %d", f.EnumMaxValues)
+	}
+
+	for i := range f.Overrides {
+		if err := f.Overrides[i].OK(); err != nil {
+			return fmt.Errorf("invalid override %d: %w", i, err)
+		}
+	}
+
+	tagKeys := map[string]bool{}
+
+	for i := range f.Tags {
+		if err := f.Tags[i].OK(); err != nil {
+			return err
+		}
+
+		if tagKeys[f.Tags[i].Key] {
+			return fmt.Errorf("duplicate tag key %q", f.Tags[i].Key)
+		}
+
+		tagKeys[f.Tags[i].Key] = true
+	}
+
+	if _, _, err := formatGoTypes(f.FormatGoTypes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkNames returns an error if the names of inputs aren't unique, or if one of them is in ReservedNames.
+func (f *FormatterOptions) checkNames(inputs []*JSONStruct) error {
+	names := map[string]bool{}
+
+	for _, input := range inputs {
+		switch {
+		case slices.Contains(f.ReservedNames, input.Name()):
+			return fmt.Errorf("type %q is already declared in the package", input.Name())
+		case names[input.Name()]:
+			return fmt.Errorf("type %q is the name of more than one input", input.Name())
+		}
+
+		names[input.Name()] = true
+	}
+
 	return nil
 }
 
+// StructFormatter renders JSONStructs, along with the structs nested within them. It is implemented by Formatter, which
+// renders Go type declarations, and SchemaFormatter, which renders a JSON Schema.
+type StructFormatter interface {
+	FormatStructs(inputs ...*JSONStruct) (string, error)
+}
+
 // Formatter prints out the contents of JSONStructs based on its configuration.
 type Formatter struct {
 	*FormatterOptions
+
+	// imports maps the package names of the types set by Overrides to their import paths, in addition to knownImports
+	imports map[string]string
+	// formatTypes maps each StringFormat to the Go type of the fields holding it: the defaults with FormatGoTypes
+	formatTypes map[StringFormat]string
+	// nullType is NullType with the import path of its package replaced by the package name
+	nullType string
+	// typeImports maps the package names of the types in FormatGoTypes and NullType to their import paths
+	typeImports map[string]string
+	// matched holds the Overrides that selected a field, and unmatched describes the others, see UnmatchedOverrides
+	matched   map[Override]bool
+	unmatched []string
+	// declaresDate is set if the structs being formatted hold dates typed as the Date type declared with them
+	declaresDate bool
 }
 
 // NewFormatter returns an initialized Formatter.
@@ -36,19 +222,111 @@ func NewFormatter(opts *FormatterOptions) (*Formatter, error) {
 		return nil, fmt.Errorf("invalid formatter options: %w", err)
 	}
 
+	formatTypes, typeImports, err := formatGoTypes(opts.FormatGoTypes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid formatter options: %w", err)
+	}
+
 	f := &Formatter{
 		FormatterOptions: opts,
+		formatTypes:      formatTypes,
+		typeImports:      typeImports,
+	}
+
+	if opts.NullType != "" {
+		typ, qualifier, importPath, err := qualifyType(opts.NullType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid formatter options: invalid null type: %w", err)
+		}
+
+		if importPath != "" {
+			existing, ok := typeImports[qualifier]
+			if !ok {
+				existing, ok = knownImports[qualifier]
+			}
+
+			if ok && existing != importPath {
+				return nil, fmt.Errorf("invalid formatter options: package name %q of %q is already used by %q",
+					qualifier, importPath, existing)
+			}
+
+			typeImports[qualifier] = importPath
+		}
+
+		f.nullType = typ
 	}
 
 	return f, nil
 }
 
+// FormatStructs renders the provided JSONStructs, as well as any structs nested within them, as Go type declarations.
+// If PackageName is set, the result is a complete Go file.
 func (f *Formatter) FormatStructs(inputs ...*JSONStruct) (string, error) {
 	// this is required by gofumpt, it's removed at the end
 	preamble := "package temp\n"
-	structStr := preamble
+
+	if err := f.applyOverrides(inputs); err != nil {
+		return "", err
+	}
+
+	if err := f.applyFormatTypes(inputs); err != nil {
+		return "", err
+	}
+
+	// the Date type is named like the ReservedNames
+	naming := f.FormatterOptions
+	if f.declaresDate {
+		opts := *f.FormatterOptions
+		opts.ReservedNames = append(slices.Clone(opts.ReservedNames), dateType)
+		naming = &opts
+	}
+
+	if err := naming.checkNames(inputs); err != nil {
+		return "", err
+	}
+
+	naming.nameStructs(inputs)
+	naming.detectEnums(inputs)
+
+	structStr, err := f.formatStructs(map[string]bool{}, inputs...)
+	if err != nil {
+		return "", err
+	}
+
+	if f.declaresDate {
+		structStr += dateDeclaration
+	}
+
+	if f.PackageName != "" {
+		return f.formatFile(structStr)
+	}
+
+	structStr = preamble + structStr
+
+	formatted, err := format.Source([]byte(structStr), format.Options{})
+	if err != nil {
+		fmt.Printf("GOFUMPT INPUT:\n%s\n", structStr)
+		return "", fmt.Errorf("failed to run gofumpt on generated structs: %w", err)
+	}
+
+	structStr = strings.ReplaceAll(string(formatted), preamble, "")
+
+	return structStr, nil
+}
+
+// formatStructs returns the unformatted type declarations for inputs and the structs nested within them. Structs with
+// the same name and shape as one that was already declared are skipped.
+func (f *Formatter) formatStructs(declared map[string]bool, inputs ...*JSONStruct) (string, error) {
+	structStr := ""
 
 	for inputNum, input := range inputs {
+		declaration := input.Name() + "\n" + input.shape()
+		if declared[declaration] {
+			continue
+		}
+
+		declared[declaration] = true
+
 		if f.SortFields {
 			input.fields.SortAlphabetically()
 		}
@@ -58,16 +336,16 @@ func (f *Formatter) FormatStructs(inputs ...*JSONStruct) (string, error) {
 			return "", fmt.Errorf("failed to format struct %d: %w", inputNum, err)
 		}
 
-		structStr += formatted
+		structStr += formatted + f.formatEnums(declared, input)
 
 		// we already inlined all the struct fields, so no need to print out their type declarations at the end
 		if f.InlineStructs {
 			continue
 		}
 
-		for _, field := range input.Fields() {
-			if field.IsStruct() || field.IsStructSlice() {
-				formatted, err := f.FormatStructs(field.GetStruct())
+		for _, field := range input.typeFields() {
+			if field.HasStruct() {
+				formatted, err := f.formatStructs(declared, field.GetStruct())
 				if err != nil {
 					return "", fmt.Errorf("failed to format nested struct: %w", err)
 				}
@@ -77,15 +355,49 @@ func (f *Formatter) FormatStructs(inputs ...*JSONStruct) (string, error) {
 		}
 	}
 
-	formatted, err := format.Source([]byte(structStr), format.Options{})
+	return structStr, nil
+}
+
+// formatFile wraps the type declarations in structStr with a package clause, the imports they require, and optionally
+// the generated header, then runs gofumpt on the result.
+func (f *Formatter) formatFile(structStr string) (string, error) {
+	packageClause := fmt.Sprintf("package %s\n\n", f.PackageName)
+
+	importPaths, err := getImports(packageClause+structStr, f.imports)
 	if err != nil {
-		fmt.Printf("GOFUMPT INPUT:\n%s\n", structStr)
-		return "", fmt.Errorf("failed to run gofumpt on generated structs: %w", err)
+		return "", fmt.Errorf("failed to determine imports: %w", err)
 	}
 
-	structStr = strings.ReplaceAll(string(formatted), preamble, "")
+	fileStr := packageClause + importBlock(importPaths) + structStr
 
-	return structStr, nil
+	if f.GeneratedHeader {
+		fileStr = GeneratedHeader + "\n\n" + fileStr
+	}
+
+	formatted, err := format.Source([]byte(fileStr), format.Options{})
+	if err != nil {
+		return "", fmt.Errorf("failed to run gofumpt on generated file: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
+// namedTypeDecl returns the declaration of a named type called name with the type typ. Types from other packages
+// (json.RawMessage, *big.Int, time.Time...) are declared as aliases of the type they point to, so that they keep the
+// methods encoding/json needs to unmarshal them.
+func namedTypeDecl(name, typ string) string {
+	if base := strings.TrimPrefix(typ, "*"); isQualifiedIdent(base) {
+		return fmt.Sprintf("type %s = %s\n\n", name, base)
+	}
+
+	return fmt.Sprintf("type %s %s\n\n", name, typ)
+}
+
+// isQualifiedIdent returns true for types like "json.RawMessage".
+func isQualifiedIdent(typ string) bool {
+	pkg, name, ok := strings.Cut(typ, ".")
+
+	return ok && token.IsIdentifier(pkg) && token.IsIdentifier(name)
 }
 
 // formatStructNetsting exists to allow us to track nesting without asking for it in FormatStructs, and so we can
@@ -93,7 +405,21 @@ func (f *Formatter) FormatStructs(inputs ...*JSONStruct) (string, error) {
 func (f *Formatter) formatStructNesting(nest int, input *JSONStruct) (string, error) {
 	structStr := ""
 
-	// here, we don't want a "type" and we don't know if this is a struct or []struct, so just leave that to formatField
+	if input.IsNamedType() {
+		fieldType, err := f.fieldType(nest, input.value)
+		if err != nil {
+			return "", err
+		}
+
+		// another name for a struct declared on its own, e.g. "type GetUser200Response = User"
+		if input.value.IsStruct() && fieldType == "*"+input.value.GetStruct().Name() {
+			return fmt.Sprintf("type %s = %s\n\n", input.Name(), input.value.GetStruct().Name()), nil
+		}
+
+		return namedTypeDecl(input.Name(), fieldType), nil
+	}
+
+	// here, we don't want a "type" and we don't know if this is a struct or []struct, so just leave that to fieldType
 	// to prepend
 	if f.InlineStructs && nest > 0 {
 		structStr += " {\n"
@@ -122,24 +448,21 @@ func (f *Formatter) formatStructNesting(nest int, input *JSONStruct) (string, er
 
 // formatField handles formatting inline structs, []structs, and regular fields.
 func (f *Formatter) formatField(nest int, field *Field) (string, error) {
-	fieldStr := ""
+	fieldType, err := f.fieldType(nest, field)
+	if err != nil {
+		return "", err
+	}
 
-	if f.InlineStructs && (field.IsStruct() || field.IsStructSlice()) {
-		inlineStruct, err := f.formatStructNesting(nest+1, field.GetStruct())
-		if err != nil {
-			return "", fmt.Errorf("failed to get nested struct: %w", err)
-		}
+	fieldStr := ""
 
-		sType := "struct"
-		if field.IsStructSlice() {
-			sType = "[]struct"
+	if doc := field.Doc(); doc != "" {
+		for _, line := range strings.Split(doc, "\n") {
+			fieldStr += strings.TrimSpace("// "+line) + "\n"
 		}
-
-		fieldStr += fmt.Sprintf("%s %s %s %s", field.Name(), sType, inlineStruct, field.Tag())
-	} else {
-		fieldStr += fmt.Sprintf("%s %s %s", field.Name(), field.Type(), field.Tag())
 	}
 
+	fieldStr += fmt.Sprintf("%s %s %s", field.Name(), fieldType, f.fieldTag(field))
+
 	if f.ValueComments {
 		fieldStr += fmt.Sprintf(" %s", field.Comment())
 	}
@@ -148,3 +471,29 @@ func (f *Formatter) formatField(nest int, field *Field) (string, error) {
 
 	return fieldStr, nil
 }
+
+// fieldType returns the type of field, with the definitions of any nested structs inlined if InlineStructs is set.
+func (f *Formatter) fieldType(nest int, field *Field) (string, error) {
+	if field.IsNull() && f.nullType != "" {
+		return f.nullType, nil
+	}
+
+	if !f.InlineStructs || !field.HasStruct() {
+		return field.Type(), nil
+	}
+
+	nested := field.GetStruct()
+	if nested.isRecursive() {
+		return "", fmt.Errorf("failed to inline %s: %w", nested.Name(), ErrRecursiveInline)
+	}
+
+	inlineStruct, err := f.formatStructNesting(nest+1, nested)
+	if err != nil {
+		return "", fmt.Errorf("failed to get nested struct: %w", err)
+	}
+
+	// e.g. "[]*Name" -> "[]struct {...}"
+	prefix := strings.TrimSuffix(field.Type(), "*"+nested.Name())
+
+	return fmt.Sprintf("%sstruct %s", prefix, inlineStruct), nil
+}
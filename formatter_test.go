@@ -14,12 +14,63 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// dateDeclaration is the Date type declared by the Formatter for date fields, as found in trimmed output.
+const dateDeclaration = `
+// Date is a time.Time written as a date without a time, e.g. "2006-01-02".
+type Date struct {
+	time.Time
+}
+
+// MarshalJSON formats the date as "2006-01-02".
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(time.DateOnly))
+}
+
+// UnmarshalJSON parses a date like "2006-01-02", leaving the date unchanged for null.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	return d.UnmarshalText([]byte(text))
+}
+
+// MarshalText formats the date as "2006-01-02".
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.Format(time.DateOnly)), nil
+}
+
+// UnmarshalText parses a date like "2006-01-02". TOML decoders pass local dates as timestamps at midnight, which are
+// accepted too.
+func (d *Date) UnmarshalText(data []byte) error {
+	parsed, err := time.Parse(time.DateOnly, string(data))
+	if err == nil {
+		d.Time = parsed
+
+		return nil
+	}
+
+	if d.Time.UnmarshalText(data) != nil {
+		return err
+	}
+
+	d.Time = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+
+	return nil
+}`
+
 func TestFormatString(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name     string
 		input    *jsonstruct.JSONStruct
+		opts     *jsonstruct.FormatterOptions
 		expected string
 	}{
 		{
@@ -29,6 +80,15 @@ func TestFormatString(t *testing.T) {
 			),
 			expected: "\ntype Simple struct {\n\tA int64 `json:\"a\"`\n}\n",
 		},
+		{
+			name: "null_type",
+			input: jsonstruct.New().AddFields(
+				jsonstruct.NewField().SetName("a").SetValue(nil),
+				jsonstruct.NewField().SetName("b").SetValue(int64(1)).SetNullable(),
+			),
+			opts:     &jsonstruct.FormatterOptions{NullType: "any"},
+			expected: "\ntype NullType struct {\n\tA any    `json:\"a\"`\n\tB *int64 `json:\"b\"`\n}\n",
+		},
 	}
 
 	formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{})
@@ -38,6 +98,14 @@ func TestFormatString(t *testing.T) {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
+
+			formatter := formatter
+			if test.opts != nil {
+				var err error
+				formatter, err = jsonstruct.NewFormatter(test.opts)
+				assert.Nil(t, err)
+			}
+
 			test.input.SetName(jsonstruct.GetGoName(test.name))
 			output, err := formatter.FormatStructs(test.input)
 			assert.Nil(t, err)
@@ -91,3 +159,366 @@ func TestFormatStringFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     *jsonstruct.FormatterOptions
+		input    *jsonstruct.JSONStruct
+		expected string
+	}{
+		{
+			name: "no_imports",
+			opts: &jsonstruct.FormatterOptions{PackageName: "models"},
+			input: jsonstruct.New().AddFields(
+				jsonstruct.NewField().SetName("a").SetValue(int64(1)),
+			),
+			expected: "package models\n\ntype NoImports struct {\n\tA int64 `json:\"a\"`\n}\n",
+		},
+		{
+			name: "one_import",
+			opts: &jsonstruct.FormatterOptions{PackageName: "models"},
+			input: jsonstruct.New().AddFields(
+				jsonstruct.NewField().SetName("a").SetValue(nil),
+			),
+			expected: "package models\n\nimport \"encoding/json\"\n\ntype OneImport struct {\n" +
+				"\tA *json.RawMessage `json:\"a\"`\n}\n",
+		},
+		{
+			name: "multiple_imports_with_header",
+			opts: &jsonstruct.FormatterOptions{PackageName: "models", GeneratedHeader: true},
+			input: jsonstruct.New().AddFields(
+				jsonstruct.NewField().SetName("a").SetValue(nil),
+				jsonstruct.NewField().SetName("b").SetValue(bigInt),
+			),
+			expected: jsonstruct.GeneratedHeader + "\n\npackage models\n\n" +
+				"import (\n\t\"encoding/json\"\n\t\"math/big\"\n)\n\n" +
+				"type MultipleImportsWithHeader struct {\n" +
+				"\tA *json.RawMessage `json:\"a\"`\n\tB *big.Int         `json:\"b\"`\n}\n",
+		},
+		{
+			name: "null_type_import",
+			opts: &jsonstruct.FormatterOptions{PackageName: "models", NullType: "gopkg.in/guregu/null.v4.String"},
+			input: jsonstruct.New().AddFields(
+				jsonstruct.NewField().SetName("a").SetValue(nil),
+				jsonstruct.NewField().SetName("b").SetValue(int64(1)),
+			),
+			expected: "package models\n\nimport \"gopkg.in/guregu/null.v4\"\n\ntype NullTypeImport struct {\n" +
+				"\tA null.String `json:\"a\"`\n\tB int64       `json:\"b\"`\n}\n",
+		},
+		{
+			name: "null_type_major_version",
+			opts: &jsonstruct.FormatterOptions{PackageName: "models", NullType: "*github.com/volatiletech/null/v8.String"},
+			input: jsonstruct.New().AddFields(
+				jsonstruct.NewField().SetName("a").SetValue(nil),
+			),
+			expected: "package models\n\nimport \"github.com/volatiletech/null/v8\"\n\ntype NullTypeMajorVersion struct {\n" +
+				"\tA *null.String `json:\"a\"`\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			formatter, err := jsonstruct.NewFormatter(test.opts)
+			assert.Nil(t, err)
+
+			test.input.SetName(jsonstruct.GetGoName(test.name))
+			output, err := formatter.FormatStructs(test.input)
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, output)
+		})
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	t.Parallel()
+
+	input := `{"day": "2024-01-02", "days": ["2024-01-02"], "events": [{"on": "2024-01-02"}, {}]}`
+
+	parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(input), slog.Default(),
+		&jsonstruct.ParserOptions{InferTime: true})
+	assert.Nil(t, err)
+
+	structs, err := parser.Start()
+	assert.Nil(t, err)
+
+	formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{PackageName: "models"})
+	assert.Nil(t, err)
+
+	output, err := formatter.FormatStructs(structs[0].SetName("Calendar"))
+	assert.Nil(t, err)
+
+	expected := "package models\n\nimport (\n\t\"encoding/json\"\n\t\"time\"\n)\n\n" +
+		"type Calendar struct {\n\tDay    Date      `json:\"day\"`\n\tDays   []Date    `json:\"days\"`\n" +
+		"\tEvents []*Events `json:\"events\"`\n}\n\ntype Events struct {\n\tOn *Date `json:\"on,omitempty\"`\n}\n" +
+		dateDeclaration + "\n"
+	assert.Equal(t, expected, output)
+
+	// another file of the package already declares Date
+	formatter, err = jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{ReservedNames: []string{"Date"}})
+	assert.Nil(t, err)
+
+	output, err = formatter.FormatStructs(structs[0].SetName("Calendar"))
+	assert.Nil(t, err)
+	assert.Contains(t, output, "Day    Date")
+	assert.NotContains(t, output, "type Date struct")
+}
+
+func TestFormatDuplicateNames(t *testing.T) {
+	t.Parallel()
+
+	goFormatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{})
+	assert.Nil(t, err)
+
+	schemaFormatter, err := jsonstruct.NewSchemaFormatter(&jsonstruct.FormatterOptions{})
+	assert.Nil(t, err)
+
+	for _, formatter := range []jsonstruct.StructFormatter{goFormatter, schemaFormatter} {
+		_, err := formatter.FormatStructs(
+			jsonstruct.New().SetName("Item").AddFields(jsonstruct.NewField().SetName("a").SetValue(int64(1))),
+			jsonstruct.New().SetName("Item").AddFields(jsonstruct.NewField().SetName("b").SetValue("x")),
+		)
+		assert.EqualError(t, err, `type "Item" is the name of more than one input`)
+	}
+}
+
+func TestFormatTopLevelMap(t *testing.T) {
+	t.Parallel()
+
+	input := `{"1": {"a": 1}, "2": {"a": 2, "b": "x"}}`
+	expected := "\ntype Users map[string]*UsersValue\n\ntype UsersValue struct {\n" +
+		"\tA int64  `json:\"a\"`\n\tB string `json:\"b,omitempty\"`\n}\n"
+
+	parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(input), slog.Default(), &jsonstruct.ParserOptions{
+		DetectMaps: true,
+	})
+	assert.Nil(t, err)
+
+	jStructs, err := parser.Start()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(jStructs))
+	assert.True(t, jStructs[0].IsNamedType())
+
+	formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{})
+	assert.Nil(t, err)
+
+	output, err := formatter.FormatStructs(jStructs[0].SetName("Users"))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, output)
+}
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestFormatDedupeStructs(t *testing.T) {
+	t.Parallel()
+
+	input := `{
+		"billing_address": {"street": "a", "zip": "1"},
+		"shipping_address": {"zip": "2", "street": "b"},
+		"orders": [{"from": {"street": "c", "zip": "3"}, "meta": {"a": 1}}],
+		"returns": [{"from": {"street": "d", "zip": "4"}, "meta": {"a": 2}}]
+	}`
+
+	tests := []struct {
+		name     string
+		opts     *jsonstruct.FormatterOptions
+		expected string
+	}{
+		{
+			name: "disabled",
+			opts: &jsonstruct.FormatterOptions{},
+			expected: "BillingAddress *BillingAddress,ShippingAddress *ShippingAddress,Orders []*Orders," +
+				"Returns []*Returns,From *From,Meta *Meta,From *From,Meta *Meta",
+		},
+		{
+			name: "first_seen",
+			opts: &jsonstruct.FormatterOptions{DedupeStructs: true},
+			expected: "BillingAddress *BillingAddress,ShippingAddress *BillingAddress,Orders []*Orders," +
+				"Returns []*Orders,From *BillingAddress,Meta *Meta",
+		},
+		{
+			name: "shortest",
+			opts: &jsonstruct.FormatterOptions{DedupeStructs: true, DedupeNaming: jsonstruct.NamingShortest},
+			expected: "BillingAddress *From,ShippingAddress *From,Orders []*Orders,Returns []*Orders," +
+				"From *From,Meta *Meta",
+		},
+		{
+			name: "type_names",
+			opts: &jsonstruct.FormatterOptions{
+				DedupeStructs: true,
+				TypeNames:     map[string]string{"ShippingAddress": "Address", "Returns": "Order"},
+			},
+			expected: "BillingAddress *Address,ShippingAddress *Address,Orders []*Order,Returns []*Order," +
+				"From *Address,Meta *Meta",
+		},
+		{
+			name:     "inline",
+			opts:     &jsonstruct.FormatterOptions{DedupeStructs: true, InlineStructs: true},
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := jsonstruct.NewParser(strings.NewReader(input), slog.Default())
+			jStructs, err := parser.Start()
+			assert.Nil(t, err)
+
+			formatter, err := jsonstruct.NewFormatter(test.opts)
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(jStructs...)
+			assert.Nil(t, err)
+
+			// collect the struct-typed fields in the order they were declared
+			fieldTypes := []string{}
+
+			for _, line := range strings.Split(output, "\n") {
+				parts := strings.Fields(line)
+				if len(parts) >= 2 && strings.HasPrefix(strings.TrimLeft(parts[1], "[]"), "*") {
+					fieldTypes = append(fieldTypes, parts[0]+" "+parts[1])
+				}
+			}
+
+			assert.Equal(t, test.expected, strings.Join(fieldTypes, ","))
+		})
+	}
+}
+
+func TestFormatCollisions(t *testing.T) {
+	t.Parallel()
+
+	input := `{
+		"order": {"items": [{"sku": "a", "qty": 1}], "metadata": {"a": 1}},
+		"cart": {"items": [{"sku": "b", "added": "x"}], "metadata": {"a": 2}},
+		"wish": {"items": [{"sku": "c", "qty": 2}]},
+		"collisions": {"x": 1}
+	}`
+
+	tests := []struct {
+		name     string
+		opts     *jsonstruct.FormatterOptions
+		expected string
+		err      bool
+	}{
+		{
+			name: "parent",
+			opts: &jsonstruct.FormatterOptions{},
+			expected: "Order *Order,Cart *Cart,Wish *Wish,Collisions *CollisionsCollisions,Items []*OrderItems," +
+				"Metadata *Metadata,Items []*CartItems,Metadata *Metadata,Items []*OrderItems",
+		},
+		{
+			name: "number",
+			opts: &jsonstruct.FormatterOptions{CollisionNaming: jsonstruct.CollisionNumber},
+			expected: "Order *Order,Cart *Cart,Wish *Wish,Collisions *Collisions2,Items []*Items," +
+				"Metadata *Metadata,Items []*Items2,Metadata *Metadata,Items []*Items",
+		},
+		{
+			name: "dedupe",
+			opts: &jsonstruct.FormatterOptions{DedupeStructs: true},
+			expected: "Order *Order,Cart *Cart,Wish *Wish,Collisions *CollisionsCollisions,Items []*OrderItems," +
+				"Metadata *Metadata,Items []*CartItems,Metadata *Metadata,Items []*OrderItems",
+		},
+		{
+			name: "reserved",
+			opts: &jsonstruct.FormatterOptions{ReservedNames: []string{"Items", "Metadata"}},
+			expected: "Order *Order,Cart *Cart,Wish *Wish,Collisions *CollisionsCollisions,Items []*OrderItems," +
+				"Metadata *OrderMetadata,Items []*CartItems,Metadata *OrderMetadata,Items []*OrderItems",
+		},
+		{
+			name: "reserved_number",
+			opts: &jsonstruct.FormatterOptions{
+				ReservedNames:   []string{"Items"},
+				CollisionNaming: jsonstruct.CollisionNumber,
+			},
+			expected: "Order *Order,Cart *Cart,Wish *Wish,Collisions *Collisions2,Items []*Items2," +
+				"Metadata *Metadata,Items []*Items3,Metadata *Metadata,Items []*Items2",
+		},
+		{
+			name: "reserved_top_level",
+			opts: &jsonstruct.FormatterOptions{ReservedNames: []string{"Collisions"}},
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := jsonstruct.NewParser(strings.NewReader(input), slog.Default())
+			jStructs, err := parser.Start()
+			assert.Nil(t, err)
+
+			formatter, err := jsonstruct.NewFormatter(test.opts)
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(jStructs[0].SetName("Collisions"))
+			if test.err {
+				assert.NotNil(t, err)
+
+				return
+			}
+
+			assert.Nil(t, err)
+
+			fieldTypes := []string{}
+			declared := map[string]bool{}
+
+			for _, line := range strings.Split(output, "\n") {
+				parts := strings.Fields(line)
+
+				switch {
+				case len(parts) >= 2 && parts[0] == "type":
+					assert.False(t, declared[parts[1]], "%s declared twice", parts[1])
+					declared[parts[1]] = true
+				case len(parts) >= 2 && strings.HasPrefix(strings.TrimLeft(parts[1], "[]"), "*"):
+					fieldTypes = append(fieldTypes, parts[0]+" "+parts[1])
+				}
+			}
+
+			assert.Equal(t, test.expected, strings.Join(fieldTypes, ","))
+		})
+	}
+}
+
+func TestFormatterOptionsOK(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		opts   *jsonstruct.FormatterOptions
+		errors bool
+	}{
+		{"empty", &jsonstruct.FormatterOptions{}, false},
+		{"package", &jsonstruct.FormatterOptions{PackageName: "models"}, false},
+		{"invalid_package", &jsonstruct.FormatterOptions{PackageName: "my-models"}, true},
+		{"header_without_package", &jsonstruct.FormatterOptions{GeneratedHeader: true}, true},
+		{"null_type", &jsonstruct.FormatterOptions{NullType: "any"}, false},
+		{"invalid_null_type", &jsonstruct.FormatterOptions{NullType: "not a type"}, true},
+		{"null_type_import_path", &jsonstruct.FormatterOptions{NullType: "gopkg.in/guregu/null.v4.String"}, false},
+		{"invalid_null_type_import_path", &jsonstruct.FormatterOptions{NullType: "example.com/null"}, true},
+		{"naming_policy", &jsonstruct.FormatterOptions{DedupeNaming: jsonstruct.NamingShortest}, false},
+		{"invalid_naming_policy", &jsonstruct.FormatterOptions{DedupeNaming: "longest"}, true},
+		{"collision_policy", &jsonstruct.FormatterOptions{CollisionNaming: jsonstruct.CollisionNumber}, false},
+		{"invalid_collision_policy", &jsonstruct.FormatterOptions{CollisionNaming: "random"}, true},
+		{"invalid_type_name", &jsonstruct.FormatterOptions{TypeNames: map[string]string{"A": "not-a-name"}}, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.opts.OK()
+			assert.Equal(t, test.errors, err != nil)
+		})
+	}
+}
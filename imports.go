@@ -0,0 +1,104 @@
+package jsonstruct
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// knownImports maps the package qualifiers that can show up in generated types to their import paths.
+//
+//nolint:gochecknoglobals
+var knownImports = map[string]string{
+	"big":   "math/big",
+	"fmt":   "fmt",
+	"json":  "encoding/json",
+	"netip": "net/netip",
+	"time":  "time",
+	"url":   "net/url",
+}
+
+// getImports parses the generated Go source in src and returns the sorted import paths required by the package
+// qualifiers it references (e.g. "json" in "*json.RawMessage"), which are looked up in extra, then in knownImports.
+// The receivers and parameters of methods, e.g. "d" in "d.Time", aren't packages.
+func getImports(src string, extra map[string]string) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse generated source: %w", err)
+	}
+
+	found := map[string]bool{}
+	locals := map[string]bool{}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncDecl:
+			for _, list := range []*ast.FieldList{node.Recv, node.Type.Params} {
+				if list == nil {
+					continue
+				}
+
+				for _, field := range list.List {
+					for _, name := range field.Names {
+						locals[name.Name] = true
+					}
+				}
+			}
+		case *ast.SelectorExpr:
+			if ident, ok := node.X.(*ast.Ident); ok {
+				found[ident.Name] = true
+			}
+		}
+
+		return true
+	})
+
+	for name := range locals {
+		delete(found, name)
+	}
+
+	results := []string{}
+
+	for qualifier := range found {
+		importPath, ok := extra[qualifier]
+		if !ok {
+			importPath, ok = knownImports[qualifier]
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("unknown import path for package %q", qualifier)
+		}
+
+		results = append(results, importPath)
+	}
+
+	sort.Strings(results)
+
+	return results, nil
+}
+
+// importBlock returns the import declaration for the provided import paths, or an empty string if there are none.
+func importBlock(importPaths []string) string {
+	switch len(importPaths) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("import %s\n\n", strconv.Quote(importPaths[0]))
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString("import (\n")
+
+	for _, importPath := range importPaths {
+		builder.WriteString(fmt.Sprintf("\t%s\n", strconv.Quote(importPath)))
+	}
+
+	builder.WriteString(")\n\n")
+
+	return builder.String()
+}
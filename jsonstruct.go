@@ -1,6 +1,10 @@
 package jsonstruct
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // JSONStruct contains the raw information about a JSON object to be rendered as a Go struct.
 type JSONStruct struct {
@@ -10,6 +14,18 @@ type JSONStruct struct {
 	nestLevel int
 	// inSlice tells the Formatter that this struct is part of a slice and should be de-duplicated rather than repeated.
 	inSlice bool
+	// value is set when this represents a named type that isn't a struct, e.g. a top-level object detected as a map.
+	value *Field
+	// named is set for structs that keep their own name wherever they are used, e.g. JSON Schema definitions, rather
+	// than being named after the fields holding them. Renaming one of those fields renames the struct.
+	named bool
+	// overrides are the Overrides set on the Parser that returned this struct, applied by the Formatter.
+	overrides []Override
+	// title is the name given to a top-level struct by its input, e.g. the title of a JSON Schema.
+	title string
+	// fromArray is set for a top-level struct merged from the objects of an array, which is rendered as an array of
+	// them in a JSON Schema.
+	fromArray bool
 }
 
 // NewJSONStruct returns an initialized JSONStruct.
@@ -20,10 +36,26 @@ func New() *JSONStruct {
 	}
 }
 
+// newNamedType returns a JSONStruct that will be rendered as a named type with the type of value rather than a struct.
+func newNamedType(value *Field) *JSONStruct {
+	return New().setValue(value)
+}
+
+func (j *JSONStruct) setValue(value *Field) *JSONStruct {
+	j.value = value
+
+	// structs nested in the value are named after it, so it can't share our name
+	return j.SetName(j.name)
+}
+
 // SetName sets the name to be used as a type for the JSONStruct.
 func (j *JSONStruct) SetName(name string) *JSONStruct {
 	if j != nil {
 		j.name = name
+
+		if j.value != nil {
+			j.value.SetName(name + "Value")
+		}
 	}
 
 	return j
@@ -34,7 +66,7 @@ func (j *JSONStruct) Name() string { return j.name }
 // AddFields appends Field objects to the JSONStruct.
 func (j *JSONStruct) AddFields(fields ...*Field) *JSONStruct {
 	for _, field := range fields {
-		if field.IsStruct() || field.IsStructSlice() {
+		if field.HasStruct() {
 			field.GetStruct().SetNestLevel(j.nestLevel + 1)
 		}
 	}
@@ -46,6 +78,18 @@ func (j *JSONStruct) AddFields(fields ...*Field) *JSONStruct {
 
 func (j *JSONStruct) Fields() Fields { return j.fields }
 
+// IsNamedType returns true if the JSONStruct represents a named non-struct type, such as a map.
+func (j *JSONStruct) IsNamedType() bool { return j.value != nil }
+
+// typeFields returns the Fields whose types are used in this JSONStruct's declaration.
+func (j *JSONStruct) typeFields() Fields {
+	if j.value != nil {
+		return Fields{j.value}
+	}
+
+	return j.fields
+}
+
 // AddInlineLevels recursively sets the inlineLevel value for this JSONStruct, as well as its struct fields.
 func (j *JSONStruct) SetNestLevel(i int) *JSONStruct {
 	if j != nil {
@@ -82,3 +126,96 @@ func anySliceToJSONStructs(input []any) (JSONStructs, error) {
 
 	return result, nil
 }
+
+// walkFields calls fn for every field holding a struct in inputs and in the structs nested within them, depth-first and
+// in the order the Formatter declares them. Each struct is only visited once, and fields referring back to one of the
+// inputs are skipped, since the inputs keep their names.
+func walkFields(inputs []*JSONStruct, fn func(parent *JSONStruct, field *Field)) {
+	visited := map[*JSONStruct]bool{}
+	isInput := map[*JSONStruct]bool{}
+
+	for _, input := range inputs {
+		isInput[input] = true
+	}
+
+	var visit func(js *JSONStruct)
+
+	visit = func(js *JSONStruct) {
+		if js == nil || visited[js] {
+			return
+		}
+
+		visited[js] = true
+
+		for _, field := range js.typeFields() {
+			if !field.HasStruct() || isInput[field.GetStruct()] {
+				continue
+			}
+
+			fn(js, field)
+			visit(field.GetStruct())
+		}
+	}
+
+	for _, input := range inputs {
+		visit(input)
+	}
+}
+
+// isRecursive returns true if j is nested within itself, which is only possible for named structs.
+func (j *JSONStruct) isRecursive() bool {
+	if !j.named {
+		return false
+	}
+
+	visited := map[*JSONStruct]bool{}
+	queue := []*JSONStruct{j}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, field := range current.typeFields() {
+			nested := field.GetStruct()
+
+			switch {
+			case nested == j:
+				return true
+			case nested != nil && !visited[nested]:
+				visited[nested] = true
+				queue = append(queue, nested)
+			}
+		}
+	}
+
+	return false
+}
+
+// shape returns a string describing the fields of the JSONStruct, their types, and their tags, as well as the shapes
+// of the structs nested within them, regardless of field order. JSONStructs with the same shape have identical
+// declarations, apart from their names and field order.
+func (j *JSONStruct) shape() string {
+	return j.shapeVisiting(map[*JSONStruct]bool{})
+}
+
+func (j *JSONStruct) shapeVisiting(visiting map[*JSONStruct]bool) string {
+	visiting[j] = true
+	defer delete(visiting, j)
+
+	fields := []string{}
+
+	for _, field := range j.typeFields() {
+		fieldStr := fmt.Sprintf("%s %s %s", field.Name(), field.Type(), field.Tag())
+
+		// recursive structs are only described by name
+		if nested := field.GetStruct(); nested != nil && !visiting[nested] {
+			fieldStr += " {" + nested.shapeVisiting(visiting) + "}"
+		}
+
+		fields = append(fields, fieldStr)
+	}
+
+	sort.Strings(fields)
+
+	return strings.Join(fields, "; ")
+}
@@ -0,0 +1,480 @@
+package jsonstruct
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrLenientSyntax = errors.New("invalid JSONC / JSON5 input")
+
+// lenientReader converts JSONC / JSON5 input to standard JSON that can be read by encoding/json: comments are removed,
+// trailing commas are dropped, unquoted keys and single-quoted strings are double-quoted, and JSON5 numbers (hex,
+// leading or trailing decimal points, explicit plus signs, Infinity and NaN) are rewritten as JSON numbers.
+//
+// The whole input is read and converted on the first call to Read. Comments attached to object keys are kept in
+// comments, keyed by the offset of the end of the key in the converted output. Comments and trailing commas are
+// replaced with whitespace, so that positions in the output match positions in the input as closely as possible.
+type lenientReader struct {
+	input     io.Reader
+	raw       []byte
+	converted *bytes.Reader
+	comments  map[int64]string
+}
+
+func newLenientReader(input io.Reader) *lenientReader {
+	return &lenientReader{input: input}
+}
+
+func (l *lenientReader) Read(p []byte) (int, error) {
+	if l.converted == nil {
+		raw, err := io.ReadAll(l.input)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read input: %w", err)
+		}
+
+		l.raw = raw
+
+		converter := &lenientConverter{input: raw, comments: map[int64]string{}}
+		if err := converter.convert(); err != nil {
+			return 0, converter.parseError(err)
+		}
+
+		l.converted = bytes.NewReader(converter.output.Bytes())
+		l.comments = converter.comments
+	}
+
+	//nolint:wrapcheck // io.EOF has to be returned as-is
+	return l.converted.Read(p)
+}
+
+// comment returns the comment attached to the key ending at offset in the converted output, if any.
+func (l *lenientReader) comment(offset int64) string {
+	return l.comments[offset]
+}
+
+// originalSnippet replaces the snippet of parseErr, which was found in the converted output, with the same position
+// in the original input.
+func (l *lenientReader) originalSnippet(parseErr *ParseError) {
+	if parseErr.Column == 0 {
+		return
+	}
+
+	lines := bytes.SplitAfterN(l.raw, []byte("\n"), parseErr.Line+1)
+	if len(lines) < parseErr.Line {
+		return
+	}
+
+	line := string(bytes.TrimRight(lines[parseErr.Line-1], "\r\n"))
+
+	// Column counts characters
+	index := 0
+	for i := 1; i < parseErr.Column && index < len(line); i++ {
+		_, size := utf8.DecodeRuneInString(line[index:])
+		index += size
+	}
+
+	parseErr.Snippet = snippet(line, index)
+}
+
+// lenientFrame is an object or array that the lenientConverter is inside of.
+type lenientFrame struct {
+	isObject bool
+	// lastKey is the offset of the end of the last key seen in this object, or -1
+	lastKey int64
+	// ownerKey is the offset of the end of the key this object or array is the value of, or -1
+	ownerKey int64
+	// hasValues is true once a value has been found in this array
+	hasValues bool
+}
+
+type lenientConverter struct {
+	input    []byte
+	pos      int
+	output   bytes.Buffer
+	comments map[int64]string
+
+	stack     []*lenientFrame
+	expectKey bool
+	// newline is true if there was a newline since the last token
+	newline bool
+	// leading contains the comments found since the last token that weren't on the same line as it
+	leading []string
+}
+
+func (c *lenientConverter) convert() error {
+	// skip the byte order mark
+	c.pos = len(c.input) - len(bytes.TrimPrefix(c.input, []byte("\ufeff")))
+
+	for c.pos < len(c.input) {
+		char := c.input[c.pos]
+
+		switch {
+		case char == '\n':
+			c.newline = true
+
+			c.output.WriteByte(char)
+			c.pos++
+		case isSpace(char):
+			c.output.WriteByte(char)
+			c.pos++
+		case char == '/':
+			if err := c.readComment(); err != nil {
+				return err
+			}
+		case char == ',':
+			c.output.WriteByte(',')
+			c.expectKey = c.inObject()
+			c.endToken()
+			c.pos++
+		case char == '}' || char == ']':
+			c.dropTrailingComma()
+			c.writeToken(string(char))
+			c.pos++
+
+			if len(c.stack) > 0 {
+				c.stack = c.stack[:len(c.stack)-1]
+			}
+
+			c.expectKey = false
+		case char == '{' || char == '[':
+			ownerKey := int64(-1)
+			if frame := c.frame(); frame != nil {
+				ownerKey = frame.lastKey
+				frame.hasValues = true
+			}
+
+			c.writeToken(string(char))
+			c.pos++
+
+			c.stack = append(c.stack, &lenientFrame{isObject: char == '{', lastKey: -1, ownerKey: ownerKey})
+			c.expectKey = char == '{'
+		case char == ':':
+			c.writeToken(":")
+			c.pos++
+		default:
+			if err := c.readValue(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseError returns a ParseError for err, found at the current position in the original input.
+func (c *lenientConverter) parseError(err error) *ParseError {
+	offset := int64(min(c.pos, len(c.input)))
+	line, column, snippet := position(c.input, offset, true)
+
+	return &ParseError{Offset: offset, Line: line, Column: column, Snippet: snippet, Err: err}
+}
+
+func (c *lenientConverter) frame() *lenientFrame {
+	if len(c.stack) == 0 {
+		return nil
+	}
+
+	return c.stack[len(c.stack)-1]
+}
+
+func (c *lenientConverter) inObject() bool {
+	frame := c.frame()
+
+	return frame != nil && frame.isObject
+}
+
+// writeToken writes token to the output.
+func (c *lenientConverter) writeToken(token string) {
+	c.output.WriteString(token)
+	c.endToken()
+}
+
+// dropTrailingComma replaces the comma before the end of an object or array with a space.
+func (c *lenientConverter) dropTrailingComma() {
+	output := c.output.Bytes()
+
+	for i := len(output) - 1; i >= 0; i-- {
+		switch {
+		case output[i] == ',':
+			output[i] = ' '
+
+			return
+		case output[i] != '\n' && !isSpace(output[i]):
+			return
+		}
+	}
+}
+
+// endToken resets the comment tracking after a token.
+func (c *lenientConverter) endToken() {
+	c.newline = false
+	c.leading = nil
+}
+
+// readValue reads a string, an unquoted key, or a literal (number, true, false, null...).
+func (c *lenientConverter) readValue() error {
+	isKey := c.expectKey && c.inObject()
+	leading := c.leading
+
+	var token string
+
+	switch char := c.input[c.pos]; {
+	case char == '"' || char == '\'':
+		str, err := c.readString(char)
+		if err != nil {
+			return err
+		}
+
+		token = str
+	case isKey:
+		token = fmt.Sprintf("%q", c.readLiteral())
+	default:
+		literal, err := c.convertLiteral(c.readLiteral())
+		if err != nil {
+			return err
+		}
+
+		token = literal
+	}
+
+	c.writeToken(token)
+
+	if frame := c.frame(); frame != nil && !isKey {
+		frame.hasValues = true
+	}
+
+	if isKey {
+		offset := int64(c.output.Len())
+		c.frame().lastKey = offset
+		c.expectKey = false
+
+		if len(leading) > 0 {
+			c.comments[offset] = strings.Join(leading, "\n")
+		}
+	}
+
+	return nil
+}
+
+// readLiteral reads the input up to the next delimiter.
+func (c *lenientConverter) readLiteral() string {
+	start := c.pos
+
+	for c.pos < len(c.input) && !isSpace(c.input[c.pos]) && !isDelimiter(c.input[c.pos]) {
+		c.pos++
+	}
+
+	if c.pos == start {
+		// unexpected character, let encoding/json complain about it
+		c.pos++
+	}
+
+	return string(c.input[start:c.pos])
+}
+
+// convertLiteral converts JSON5 numbers to JSON numbers, and returns anything else unchanged.
+func (c *lenientConverter) convertLiteral(literal string) (string, error) {
+	number := strings.TrimPrefix(literal, "+")
+	sign := ""
+
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+
+	lower := strings.ToLower(number)
+
+	switch {
+	case number == "Infinity" || number == "NaN":
+		// these can't be represented in JSON, but they're still floats
+		return "0.0", nil
+	case strings.HasPrefix(lower, "0x"):
+		hexInt, ok := (&big.Int{}).SetString(number[2:], 16)
+		if !ok {
+			return "", fmt.Errorf("%w: invalid hexadecimal number %q", ErrLenientSyntax, literal)
+		}
+
+		return sign + hexInt.String(), nil
+	case strings.HasPrefix(number, "."):
+		number = "0" + number
+	}
+
+	// "5." or "5.e3"
+	if dotIndex := strings.Index(number, "."); dotIndex != -1 &&
+		(dotIndex == len(number)-1 || !isNumber(rune(number[dotIndex+1]))) {
+		number = number[:dotIndex+1] + "0" + number[dotIndex+1:]
+	}
+
+	if number != literal {
+		return sign + number, nil
+	}
+
+	return literal, nil
+}
+
+// readString reads a string delimited by quote and returns it as a double-quoted JSON string.
+func (c *lenientConverter) readString(quote byte) (string, error) {
+	var result strings.Builder
+
+	result.WriteByte('"')
+
+	start := c.pos
+
+	for c.pos++; c.pos < len(c.input); c.pos++ {
+		char := c.input[c.pos]
+
+		switch {
+		case char == quote:
+			c.pos++
+			result.WriteByte('"')
+
+			return result.String(), nil
+		case char == '"':
+			result.WriteString(`\"`)
+		case char == '\\':
+			if err := c.readEscape(&result); err != nil {
+				return "", err
+			}
+		default:
+			result.WriteByte(char)
+		}
+	}
+
+	c.pos = start
+
+	return "", fmt.Errorf("%w: unterminated string", ErrLenientSyntax)
+}
+
+// readEscape converts the escape sequence starting at the current position to a JSON escape sequence.
+func (c *lenientConverter) readEscape(result *strings.Builder) error {
+	c.pos++
+	if c.pos >= len(c.input) {
+		return fmt.Errorf("%w: unterminated string", ErrLenientSyntax)
+	}
+
+	switch char := c.input[c.pos]; char {
+	case '\n':
+		// line continuation
+	case '\r':
+		if c.pos+1 < len(c.input) && c.input[c.pos+1] == '\n' {
+			c.pos++
+		}
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't', 'u':
+		result.WriteByte('\\')
+		result.WriteByte(char)
+	case 'x':
+		if c.pos+2 >= len(c.input) || !isHex(rune(c.input[c.pos+1])) || !isHex(rune(c.input[c.pos+2])) {
+			return fmt.Errorf("%w: invalid \\x escape", ErrLenientSyntax)
+		}
+
+		result.WriteString(`\u00`)
+		result.Write(c.input[c.pos+1 : c.pos+3])
+
+		c.pos += 2
+	case 'v':
+		result.WriteString(`\u000b`)
+	case '0':
+		result.WriteString(`\u0000`)
+	default:
+		// any other character escapes to itself
+		result.WriteByte(char)
+	}
+
+	return nil
+}
+
+// readComment reads a "//" or "/* */" comment. Comments on the same line as the end of an object member are attached
+// to its key, and other comments are attached to the key that follows them.
+func (c *lenientConverter) readComment() error {
+	if c.pos+1 >= len(c.input) || (c.input[c.pos+1] != '/' && c.input[c.pos+1] != '*') {
+		return fmt.Errorf("%w: unexpected '/'", ErrLenientSyntax)
+	}
+
+	var text string
+
+	if c.input[c.pos+1] == '/' {
+		end := bytes.IndexByte(c.input[c.pos:], '\n')
+		if end == -1 {
+			end = len(c.input) - c.pos
+		}
+
+		text = string(c.input[c.pos+2 : c.pos+end])
+		c.blankOut(c.input[c.pos : c.pos+end])
+		c.pos += end
+	} else {
+		end := bytes.Index(c.input[c.pos+2:], []byte("*/"))
+		if end == -1 {
+			return fmt.Errorf("%w: unterminated comment", ErrLenientSyntax)
+		}
+
+		text = blockCommentText(string(c.input[c.pos+2 : c.pos+2+end]))
+		c.blankOut(c.input[c.pos : c.pos+end+4])
+		c.pos += end + 4
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	frame := c.frame()
+	if c.newline || frame == nil {
+		c.leading = append(c.leading, text)
+
+		return nil
+	}
+
+	// a comment at the end of a line belongs to the member on that line, or to the key that opened the object / array
+	// if it's the first thing on the line
+	key := frame.lastKey
+	if key == -1 && !frame.hasValues {
+		key = frame.ownerKey
+	}
+
+	if key == -1 {
+		c.leading = append(c.leading, text)
+	} else if existing := c.comments[key]; existing != "" {
+		c.comments[key] = existing + "\n" + text
+	} else {
+		c.comments[key] = text
+	}
+
+	return nil
+}
+
+// blankOut writes a space to the output for every character in removed, keeping newlines and tabs, so that the
+// positions of the tokens that follow don't change.
+func (c *lenientConverter) blankOut(removed []byte) {
+	for _, r := range string(removed) {
+		switch r {
+		case '\n', '\t':
+			c.output.WriteRune(r)
+		default:
+			c.output.WriteByte(' ')
+		}
+	}
+}
+
+// blockCommentText removes the leading "*" from the lines of a "/* */" comment.
+func blockCommentText(text string) string {
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(strings.TrimSpace(line), "*")
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func isDelimiter(char byte) bool {
+	return strings.IndexByte(",:[]{}/\"'\n", char) != -1
+}
+
+func isSpace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\r' || char == '\v' || char == '\f'
+}
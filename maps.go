@@ -0,0 +1,169 @@
+package jsonstruct
+
+import (
+	"strings"
+)
+
+// mapKeyThreshold is the number of keys above which an object whose values all share the same shape is assumed to be
+// a map rather than a struct, even if its keys look like regular field names.
+const mapKeyThreshold = 20
+
+// jsonMap contains the members of a JSON object whose keys look like data rather than field names. It is rendered as a
+// map[string]T, where T is inferred by merging all of its values.
+type jsonMap struct {
+	fields Fields
+}
+
+func newJSONMap(js *JSONStruct) *jsonMap {
+	return &jsonMap{fields: js.Fields()}
+}
+
+// values returns the values of all the members of the map, in order.
+func (m *jsonMap) values() []any {
+	results := make([]any, 0, len(m.fields))
+
+	for _, field := range m.fields {
+		results = append(results, field.rawValue)
+	}
+
+	return results
+}
+
+// looksLikeMap returns true if the keys of js look like data (IDs, dates, UUIDs, hostnames...) rather than a schema,
+// or if js has a large number of keys whose values all share the same shape.
+func looksLikeMap(js *JSONStruct) bool {
+	fields := js.Fields()
+	if len(fields) == 0 {
+		return false
+	}
+
+	values := withoutNulls(newJSONMap(js).values())
+	if _, ok := mergeValues(values); !ok {
+		return false
+	}
+
+	dataKeys := 0
+
+	for _, field := range fields {
+		if isDataKey(field.OriginalName()) {
+			dataKeys++
+		}
+	}
+
+	if dataKeys == len(fields) {
+		return true
+	}
+
+	return len(fields) >= mapKeyThreshold && isStructSlice(values) && sameShape(values)
+}
+
+// sameShape returns true if all of the *JSONStructs in values have the same keys.
+func sameShape(values []any) bool {
+	jStructs, err := anySliceToJSONStructs(values)
+	if err != nil {
+		return false
+	}
+
+	first := jStructs[0].Fields()
+
+	for _, jStruct := range jStructs[1:] {
+		fields := jStruct.Fields()
+		if len(fields) != len(first) {
+			return false
+		}
+
+		for i, field := range fields {
+			if field.OriginalName() != first[i].OriginalName() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isDataKey returns true if key looks like a piece of data rather than a field name.
+func isDataKey(key string) bool {
+	switch {
+	case isNumeric(key), isUUID(key), timeFormat(key) != "", isHostname(key), isNumberedIdentifier(key):
+		return true
+	}
+
+	return false
+}
+
+func isNumeric(input string) bool {
+	input = strings.TrimPrefix(input, "-")
+	if input == "" {
+		return false
+	}
+
+	for _, r := range input {
+		if !isNumber(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isUUID returns true for strings of the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx, where x is a hex digit.
+func isUUID(input string) bool {
+	if len(input) != 36 {
+		return false
+	}
+
+	for i, r := range input {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !isHex(r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isHostname returns true for dotted names such as "web-1.example.com" or "10.0.0.1".
+func isHostname(input string) bool {
+	labels := strings.Split(input, ".")
+	if len(labels) < 2 {
+		return false
+	}
+
+	for _, label := range labels {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+
+		for _, r := range label {
+			if !isAlphaNum(r) && r != '-' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isNumberedIdentifier returns true for keys like "user_123" or "order-0042": a word, a separator, then at least two
+// digits.
+func isNumberedIdentifier(input string) bool {
+	sepIndex := strings.LastIndexAny(input, "_-:")
+	if sepIndex < 1 {
+		return false
+	}
+
+	suffix := input[sepIndex+1:]
+
+	return len(suffix) >= 2 && isNumeric(suffix)
+}
+
+func isHex(r rune) bool {
+	return isNumber(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
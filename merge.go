@@ -0,0 +1,206 @@
+package jsonstruct
+
+import (
+	"math/big"
+	"reflect"
+)
+
+// withoutNulls returns the non-null values in values.
+func withoutNulls(values []any) []any {
+	results := make([]any, 0, len(values))
+
+	for _, value := range values {
+		if value != nil {
+			results = append(results, value)
+		}
+	}
+
+	return results
+}
+
+// numberKind is the position of a numeric type in the lattice used to merge numbers of different types.
+type numberKind int
+
+const (
+	notNumber numberKind = iota
+	kindInt64
+	kindBigInt
+	kindFloat64
+	kindBigFloat
+)
+
+// mergeValues returns a single value that represents all of the provided values, or false if they can't be represented
+// by a single Go type. Structs are merged the way they are in slices of structs, maps are merged into a single map,
+// slices are concatenated, and everything else is merged pairwise with unifyValues.
+func mergeValues(values []any) (any, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	if isStructSlice(values) {
+		return getSliceStruct(values), true
+	}
+
+	merged := &jsonMap{}
+	slices := []any{}
+	allMaps, allSlices := true, true
+
+	for _, value := range values {
+		if jMap, ok := value.(*jsonMap); ok {
+			merged.fields = append(merged.fields, jMap.fields...)
+		} else {
+			allMaps = false
+		}
+
+		if value != nil && reflect.TypeOf(value).Kind() == reflect.Slice {
+			slices = append(slices, anySlice(value)...)
+		} else {
+			allSlices = false
+		}
+	}
+
+	switch {
+	case allMaps:
+		return merged, true
+	case allSlices:
+		return slices, true
+	}
+
+	result := values[0]
+
+	for _, value := range values[1:] {
+		var ok bool
+		if result, ok = unifyValues(result, value); !ok {
+			return nil, false
+		}
+	}
+
+	return result, true
+}
+
+// unifyValues returns a value with a type that can hold both a and b, or false if there isn't one. The value returned
+// is a, converted to that type if necessary. Numbers are widened (int64 + float64 -> float64, int64 + *big.Int ->
+// *big.Int, and anything else -> *big.Float), and strings recognized as different formats become plain strings unless
+// unifyFormats finds a format for both.
+func unifyValues(a, b any) (any, bool) {
+	aStr, aIsStr := stringValue(a)
+	_, bIsStr := stringValue(b)
+
+	switch {
+	case a == nil || b == nil:
+		return a, a == b
+	case aIsStr && bIsStr:
+		if stringFormat(a) == stringFormat(b) {
+			return a, true
+		}
+
+		aFormatted, aOK := a.(formattedString)
+		bFormatted, bOK := b.(formattedString)
+
+		if aOK && bOK {
+			if format := unifyFormats(aFormatted, bFormatted); format != "" {
+				return formattedString{value: aFormatted.value, format: format}, true
+			}
+		}
+
+		return aStr, true
+	case reflect.TypeOf(a) == reflect.TypeOf(b):
+		return a, true
+	}
+
+	aKind, bKind := getNumberKind(a), getNumberKind(b)
+	if aKind == notNumber || bKind == notNumber {
+		return nil, false
+	}
+
+	return convertNumber(a, unifyNumberKinds(aKind, bKind)), true
+}
+
+// unifyFormats returns a StringFormat describing the values of both a and b, which have different formats, or an empty
+// string if there isn't one.
+func unifyFormats(a, b formattedString) StringFormat {
+	switch {
+	case a.isNumeric() && b.isNumeric():
+		// e.g. "1" and "1.5" are both numbers
+		return FormatNumber
+	case isIPFormat(a.format) && isIPFormat(b.format):
+		return FormatIP
+	}
+
+	return ""
+}
+
+// isIPFormat returns true for the formats of IP addresses.
+func isIPFormat(format StringFormat) bool {
+	return format == FormatIPv4 || format == FormatIPv6 || format == FormatIP
+}
+
+func getNumberKind(input any) numberKind {
+	switch input.(type) {
+	case int64:
+		return kindInt64
+	case *big.Int:
+		return kindBigInt
+	case float64:
+		return kindFloat64
+	case *big.Float:
+		return kindBigFloat
+	}
+
+	return notNumber
+}
+
+func unifyNumberKinds(a, b numberKind) numberKind {
+	switch {
+	case a == b:
+		return a
+	case a == kindInt64 && b == kindBigInt, a == kindBigInt && b == kindInt64:
+		return kindBigInt
+	case a == kindInt64 && b == kindFloat64, a == kindFloat64 && b == kindInt64:
+		return kindFloat64
+	}
+
+	// *big.Int + float64, or anything + *big.Float
+	return kindBigFloat
+}
+
+// convertNumber converts input, which must be one of the numeric types produced by the Parser, to kind.
+func convertNumber(input any, kind numberKind) any {
+	switch val := input.(type) {
+	case int64:
+		switch kind {
+		case kindBigInt:
+			return big.NewInt(val)
+		case kindFloat64:
+			return float64(val)
+		case kindBigFloat:
+			return (&big.Float{}).SetInt64(val)
+		}
+	case *big.Int:
+		if kind == kindBigFloat {
+			return (&big.Float{}).SetInt(val)
+		}
+	case float64:
+		if kind == kindBigFloat {
+			return big.NewFloat(val)
+		}
+	}
+
+	return input
+}
+
+// anySlice converts input, which must be a slice, to a []any.
+func anySlice(input any) []any {
+	if result, ok := input.([]any); ok {
+		return result
+	}
+
+	rawVal := reflect.ValueOf(input)
+	result := make([]any, 0, rawVal.Len())
+
+	for i := 0; i < rawVal.Len(); i++ {
+		result = append(result, rawVal.Index(i).Interface())
+	}
+
+	return result
+}
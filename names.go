@@ -0,0 +1,141 @@
+package jsonstruct
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultNamePattern is the template used to name top-level structs when no name is provided, e.g. "Users1" for the
+// first document in users.json.
+const DefaultNamePattern = "{{.Name}}{{.Index}}"
+
+// NameData is passed to the templates used by TypeNamer.
+type NameData struct {
+	// Name is derived from the name of the input, e.g. "Users" for "users.json" or "Stdin" for STDIN.
+	Name string
+	// Index is the position of the document within its input, starting at 1.
+	Index int
+	// Count is the number of documents in the input.
+	Count int
+	// Number is the position of the document across all inputs, starting at 1.
+	Number int
+}
+
+// TypeNamer names the top-level structs returned by Parsers. It is created from a pattern that can be:
+//
+//   - a template like "{{.Name}}{{.Index}}", which is executed with NameData for each struct
+//   - a comma-separated list of names like "User,Group", which are used in order across all inputs
+//   - a single name like "User", which is used for the first struct, followed by "User2", "User3", etc.
+//
+// All names are normalized with GetGoName, and it's an error to give the same name to more than one struct.
+type TypeNamer struct {
+	tmpl   *template.Template
+	names  []string
+	number int
+	// useTitles names structs with a title after it, when no pattern was provided
+	useTitles bool
+	// used holds the names given so far
+	used map[string]bool
+}
+
+// NewTypeNamer returns a TypeNamer for pattern. If it's empty, structs are named after their title if they have one,
+// e.g. the title of a JSON Schema, and with DefaultNamePattern otherwise.
+func NewTypeNamer(pattern string) (*TypeNamer, error) {
+	namer := &TypeNamer{useTitles: pattern == "", used: map[string]bool{}}
+
+	if pattern == "" {
+		pattern = DefaultNamePattern
+	}
+
+	if strings.Contains(pattern, "{{") {
+		tmpl, err := template.New("name").Option("missingkey=error").Parse(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse name template %q: %w", pattern, err)
+		}
+
+		namer.tmpl = tmpl
+
+		return namer, nil
+	}
+
+	for _, name := range strings.Split(pattern, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("empty name in %q", pattern)
+		}
+
+		namer.names = append(namer.names, name)
+	}
+
+	return namer, nil
+}
+
+// NameStructs sets the names of jStructs, which were all parsed from the input called inputName.
+func (t *TypeNamer) NameStructs(inputName string, jStructs JSONStructs) error {
+	for i, jStruct := range jStructs {
+		t.number++
+
+		name := jStruct.title
+		if !t.useTitles || name == "" {
+			var err error
+
+			name, err = t.name(NameData{
+				Name:   GetFileGoName(inputName),
+				Index:  i + 1,
+				Count:  len(jStructs),
+				Number: t.number,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		name = GetGoName(name)
+		if t.used[name] {
+			if t.tmpl != nil {
+				return fmt.Errorf("name %q is given to more than one struct, the template needs e.g. {{.Index}} or "+
+					"{{.Number}} to tell them apart", name)
+			}
+
+			return fmt.Errorf("name %q is given to more than one struct", name)
+		}
+
+		t.used[name] = true
+
+		jStruct.SetName(name)
+	}
+
+	return nil
+}
+
+// Done returns an error if a list of names was provided with more names than there were structs to name.
+func (t *TypeNamer) Done() error {
+	if len(t.names) > 1 && t.number < len(t.names) {
+		return fmt.Errorf("found %d structs for the %d names provided", t.number, len(t.names))
+	}
+
+	return nil
+}
+
+func (t *TypeNamer) name(data NameData) (string, error) {
+	switch {
+	case t.tmpl != nil:
+		var result strings.Builder
+		if err := t.tmpl.Execute(&result, data); err != nil {
+			return "", fmt.Errorf("failed to execute name template: %w", err)
+		}
+
+		return result.String(), nil
+	case len(t.names) > 1:
+		if data.Number > len(t.names) {
+			return "", fmt.Errorf("found more than the %d names provided", len(t.names))
+		}
+
+		return t.names[data.Number-1], nil
+	case data.Number > 1:
+		return fmt.Sprintf("%s%d", t.names[0], data.Number), nil
+	}
+
+	return t.names[0], nil
+}
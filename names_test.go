@@ -0,0 +1,82 @@
+package jsonstruct_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+func TestTypeNamer(t *testing.T) {
+	t.Parallel()
+
+	type input struct {
+		name  string
+		count int
+	}
+
+	tests := []struct {
+		name     string
+		pattern  string
+		inputs   []input
+		expected string
+		errors   bool
+	}{
+		{"default", "", []input{{"users.json", 2}, {"/dev/stdin", 1}}, "Users1,Users2,Stdin1", false},
+		{"single_name", "user", []input{{"users.json", 1}}, "User", false},
+		{"single_name_numbered", "User", []input{{"a.json", 2}, {"b.json", 1}}, "User,User2,User3", false},
+		{"list", "User, group,Role", []input{{"a.json", 2}, {"b.json", 1}}, "User,Group,Role", false},
+		{"list_too_short", "User,Group", []input{{"a.json", 3}}, "", true},
+		{"list_too_long", "User,Group,Role", []input{{"a.json", 2}}, "User,Group", true},
+		{"list_duplicate", "User,Group,User", []input{{"a.json", 3}}, "", true},
+		{"list_empty_name", "User,,Group", nil, "", true},
+		{"template", "{{.Name}}_v{{.Number}}", []input{{"a.json", 2}, {"b.json", 1}}, "AV1,AV2,BV3", false},
+		{
+			name:     "template_conditional",
+			pattern:  "{{.Name}}{{if gt .Count 1}}{{.Index}}{{end}}",
+			inputs:   []input{{"a.json", 2}, {"b.json", 1}},
+			expected: "A1,A2,B",
+		},
+		{"template_duplicate", "{{.Name}}", []input{{"a.json", 2}}, "", true},
+		{"template_duplicate_number", "{{.Name}}", []input{{"a.json", 1}, {"a.json", 1}}, "A", true},
+		{"invalid_template", "{{.Name", nil, "", true},
+		{"unknown_template_field", "{{.Unknown}}", []input{{"a.json", 1}}, "", true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			namer, err := jsonstruct.NewTypeNamer(test.pattern)
+			if err == nil {
+				names := []string{}
+
+				for _, input := range test.inputs {
+					jStructs := jsonstruct.JSONStructs{}
+					for i := 0; i < input.count; i++ {
+						jStructs = append(jStructs, jsonstruct.New())
+					}
+
+					if err = namer.NameStructs(input.name, jStructs); err != nil {
+						break
+					}
+
+					for _, jStruct := range jStructs {
+						names = append(names, jStruct.Name())
+					}
+				}
+
+				if err == nil {
+					err = namer.Done()
+				}
+
+				assert.Equal(t, test.expected, strings.Join(names, ","))
+			}
+
+			assert.Equal(t, test.errors, err != nil)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package jsonstruct
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var ErrIncompatibleSamples = errors.New("samples can't be represented by a single type")
+
+// startNDJSON merges every top-level value into a single JSONStruct, as if they were the elements of one array. Arrays
+// of objects contribute each of their elements. The samples are merged one at a time, and the merged value is
+// compacted after each of them, so the input never has to be held in memory.
+func (p *Parser) startNDJSON() (JSONStructs, error) {
+	var (
+		merged any
+		ok     bool
+	)
+
+	for count := 1; ; count++ {
+		p.path = []string{rootKey}
+
+		first, err := p.peek()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, p.parseError(fmt.Errorf("failed to start parser: %w", err))
+		}
+
+		samples, err := p.parseSamples(first)
+		if err != nil {
+			return nil, p.parseError(fmt.Errorf("failed to parse value %d: %w", count, err))
+		}
+
+		if merged, ok = mergeSamples(merged, samples); !ok {
+			return nil, p.parseError(fmt.Errorf("value %d: %w", count, ErrIncompatibleSamples))
+		}
+	}
+
+	return mergedStructs(merged)
+}
+
+// mergeSamples merges samples into merged, which is nil for the first ones, and returns the compacted result, or false
+// if they can't be merged.
+func mergeSamples(merged any, samples []any) (any, bool) {
+	for _, sample := range samples {
+		if merged != nil {
+			sample, _ = mergeValues([]any{merged, sample})
+		}
+
+		if merged = compactSample(sample); merged == nil {
+			return nil, false
+		}
+	}
+
+	return merged, true
+}
+
+// mergedStructs returns the JSONStruct for the merged samples, or nothing if there weren't any.
+func mergedStructs(merged any) (JSONStructs, error) {
+	switch val := merged.(type) {
+	case nil:
+		return JSONStructs{}, nil
+	case *JSONStruct:
+		return JSONStructs{val}, nil
+	case *jsonMap:
+		return JSONStructs{newNamedType(NewField().SetValue(val))}, nil
+	}
+
+	return nil, fmt.Errorf("expecting objects, got %T: %w", merged, ErrIncompatibleSamples)
+}
+
+// parseSamples parses the next top-level value, starting with first, into a list of samples.
+func (p *Parser) parseSamples(first any) ([]any, error) {
+	delim, ok := first.(json.Delim)
+	if !ok {
+		return nil, fmt.Errorf("expecting to start with a json.Delim, got %+v", first)
+	}
+
+	if delim == '[' {
+		samples, err := p.parseArray()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse array: %w", err)
+		}
+
+		return samples, nil
+	}
+
+	js, err := p.parseObject()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse object: %w", err)
+	}
+
+	return []any{p.mapOrStruct(rootKey, js)}, nil
+}
+
+// compactSample returns the compacted form of a merged sample, or nil if it isn't an object.
+func compactSample(sample any) any {
+	switch sample.(type) {
+	case *JSONStruct, *jsonMap:
+		return compactValue(sample)
+	}
+
+	return nil
+}
+
+// compactValue shrinks a merged value without changing the type it will be rendered as, so that merging more samples
+// into it doesn't depend on the size of the previous ones: the elements of slices and the values of maps are merged
+// into a single element, followed by a null if there were any.
+func compactValue(value any) any {
+	switch val := value.(type) {
+	case *JSONStruct:
+		for _, field := range val.Fields() {
+			field.SetValue(compactValue(field.rawValue))
+		}
+	case *jsonMap:
+		if len(val.fields) == 0 {
+			return val
+		}
+
+		compacted := &jsonMap{}
+
+		for _, element := range compactElements(val.values()) {
+			field := *val.fields[0]
+			compacted.fields = append(compacted.fields, field.SetValue(element))
+		}
+
+		return compacted
+	case []any:
+		return compactElements(val)
+	}
+
+	return value
+}
+
+// compactElements merges elements into a single element, followed by a null if there were any. If the elements can't
+// be merged, the first element of each type is kept instead, so that they still can't be merged.
+func compactElements(elements []any) []any {
+	nonNull := withoutNulls(elements)
+	if len(nonNull) == 0 {
+		return elements[:min(len(elements), 1)]
+	}
+
+	results := []any{}
+
+	if merged, ok := mergeValues(nonNull); ok {
+		results = append(results, compactValue(merged))
+	} else {
+		seen := map[reflect.Type]bool{}
+
+		for _, element := range nonNull {
+			if elementType := reflect.TypeOf(element); !seen[elementType] {
+				seen[elementType] = true
+
+				results = append(results, compactValue(element))
+			}
+		}
+	}
+
+	if len(nonNull) != len(elements) {
+		results = append(results, nil)
+	}
+
+	return results
+}
@@ -0,0 +1,351 @@
+package jsonstruct
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"strings"
+)
+
+var ErrInvalidOpenAPI = errors.New("invalid OpenAPI document")
+
+// openAPIMethods lists the operations of a path item, in the order they are walked.
+//
+//nolint:gochecknoglobals
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// OpenAPIOptions defines how the OpenAPIParser builds types for the bodies of an OpenAPI document.
+type OpenAPIOptions struct {
+	// ParserOptions defines how the examples of bodies are parsed. NDJSON and Lenient don't apply to them and are
+	// ignored.
+	ParserOptions *ParserOptions
+
+	// PreferSchemas builds types from the schemas of bodies even when they have examples, which are only used for
+	// bodies without a schema.
+	PreferSchemas bool
+}
+
+// OK ensures that the options passed in are valid.
+func (o *OpenAPIOptions) OK() error {
+	if o.ParserOptions == nil {
+		return fmt.Errorf("parser options are required")
+	}
+
+	if err := o.ParserOptions.OK(); err != nil {
+		return fmt.Errorf("invalid parser options: %w", err)
+	}
+
+	return nil
+}
+
+// OpenAPIParser builds JSONStructs for the request and response bodies of every operation in an OpenAPI 3.x document,
+// in JSON or YAML. Bodies are named after the operationId of their operation (or its method and path if it doesn't
+// have one), followed by "Request" or the status code and "Response", e.g. "ListUsers200Response".
+//
+// Only JSON bodies are used, preferring application/json. Their types are built from their examples, which are parsed
+// by a Parser, or from their schemas if they don't have any, which are built by a SchemaParser sharing the components
+// of the whole document, so that they are only declared once.
+type OpenAPIParser struct {
+	*OpenAPIOptions
+
+	log     *slog.Logger
+	input   io.Reader
+	schemas *SchemaParser
+}
+
+// NewOpenAPIParser returns an OpenAPIParser reading an OpenAPI document from input.
+func NewOpenAPIParser(input io.Reader, logger *slog.Logger, opts *OpenAPIOptions) (*OpenAPIParser, error) {
+	if err := opts.OK(); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI options: %w", err)
+	}
+
+	return &OpenAPIParser{
+		OpenAPIOptions: opts,
+		log:            logger,
+		input:          input,
+	}, nil
+}
+
+// Start decodes the document and returns a JSONStruct for each body, in the order of the paths, their operations, and
+// their responses. Bodies that aren't JSON, or don't have an example or a schema, are skipped.
+func (o *OpenAPIParser) Start() (JSONStructs, error) {
+	doc, err := decodeDocument(o.input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAPI document: %w", err)
+	}
+
+	root, ok := doc.(*schemaObject)
+	if !ok {
+		return nil, fmt.Errorf("expecting an object, got %T: %w", doc, ErrInvalidOpenAPI)
+	}
+
+	if version, _ := root.str("openapi"); !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported version %q, expecting 3.x: %w", version, ErrInvalidOpenAPI)
+	}
+
+	o.schemas = newDocumentSchemaParser(root, o.log)
+
+	results := JSONStructs{}
+	paths, _ := root.values["paths"].(*schemaObject)
+
+	if paths == nil {
+		return results, nil
+	}
+
+	for _, path := range paths.keys {
+		pointer := "#/paths/" + pointerToken(path)
+
+		pathItem, err := o.resolve(pointer, paths.values[path])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, method := range openAPIMethods {
+			operation, ok := pathItem.values[method].(*schemaObject)
+			if !ok {
+				continue
+			}
+
+			structs, err := o.operationStructs(pointer+"/"+method, operationName(method, path, operation), operation)
+			if err != nil {
+				return nil, err
+			}
+
+			results = append(results, structs...)
+		}
+	}
+
+	return results, nil
+}
+
+// operationName returns the name of the types for the bodies of operation, which is found under method and path.
+func operationName(method, path string, operation *schemaObject) string {
+	if operationID, _ := operation.str("operationId"); operationID != "" {
+		return operationID
+	}
+
+	// e.g. "get users id" for GET /users/{id}
+	return method + strings.NewReplacer("/", " ", "{", " ", "}", " ").Replace(path)
+}
+
+// operationStructs returns the JSONStructs for the request body and the responses of operation, found at pointer.
+func (o *OpenAPIParser) operationStructs(pointer, name string, operation *schemaObject) (JSONStructs, error) {
+	results := JSONStructs{}
+
+	if body, ok := operation.values["requestBody"]; ok {
+		js, err := o.bodyStruct(pointer+"/requestBody", body)
+		if err != nil {
+			return nil, err
+		}
+
+		if js != nil {
+			results = append(results, js.SetName(GetGoName(name+" request")))
+		}
+	}
+
+	responses, _ := operation.values["responses"].(*schemaObject)
+	if responses == nil {
+		return results, nil
+	}
+
+	for _, status := range responses.keys {
+		js, err := o.bodyStruct(pointer+"/responses/"+pointerToken(status), responses.values[status])
+		if err != nil {
+			return nil, err
+		}
+
+		if js != nil {
+			results = append(results, js.SetName(GetGoName(name+" "+status+" response")))
+		}
+	}
+
+	return results, nil
+}
+
+// bodyStruct returns the JSONStruct for body, a Request Body or Response Object found at pointer, or nil if it doesn't
+// have a JSON body with an example or a schema.
+func (o *OpenAPIParser) bodyStruct(pointer string, body any) (*JSONStruct, error) {
+	bodyObj, err := o.resolve(pointer, body)
+	if err != nil {
+		return nil, err
+	}
+
+	content, _ := bodyObj.values["content"].(*schemaObject)
+
+	mediaType, mediaTypeKey := jsonMediaType(content)
+	if mediaType == nil {
+		return nil, nil
+	}
+
+	pointer += "/content/" + pointerToken(mediaTypeKey)
+
+	examples, err := o.examples(pointer, mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, hasSchema := mediaType.values["schema"]
+
+	switch {
+	case len(examples) > 0 && (!o.PreferSchemas || !hasSchema):
+		return o.exampleStruct(pointer, examples)
+	case !hasSchema:
+		o.log.Debug("skipping body without examples or a schema", "path", pointer)
+
+		return nil, nil
+	}
+
+	js, err := o.schemas.rootStruct(pointer+"/schema", schema, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build schema: %w", err)
+	}
+
+	// named structs, e.g. components, keep their names, so the body gets its own name for them
+	if js.named {
+		return newNamedType(NewField().SetValue(js)), nil
+	}
+
+	return js, nil
+}
+
+// jsonMediaType returns the Media Type Object for application/json in content, or for the first other JSON media type
+// (e.g. application/problem+json) if there isn't one, along with its key.
+func jsonMediaType(content *schemaObject) (*schemaObject, string) {
+	if content == nil {
+		return nil, ""
+	}
+
+	result, resultKey := (*schemaObject)(nil), ""
+
+	for _, key := range content.keys {
+		mediaType, _, err := mime.ParseMediaType(key)
+		if err != nil || !strings.Contains(mediaType, "json") {
+			continue
+		}
+
+		obj, ok := content.values[key].(*schemaObject)
+		if !ok {
+			continue
+		}
+
+		if mediaType == "application/json" {
+			return obj, key
+		}
+
+		if result == nil {
+			result, resultKey = obj, key
+		}
+	}
+
+	return result, resultKey
+}
+
+// examples returns the example values of mediaType, found at pointer. External examples aren't fetched.
+func (o *OpenAPIParser) examples(pointer string, mediaType *schemaObject) ([]any, error) {
+	if example, ok := mediaType.values["example"]; ok {
+		return []any{example}, nil
+	}
+
+	results := []any{}
+
+	examples, _ := mediaType.values["examples"].(*schemaObject)
+	if examples == nil {
+		return results, nil
+	}
+
+	for _, key := range examples.keys {
+		example, err := o.resolve(pointer+"/examples/"+pointerToken(key), examples.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		if value, ok := example.values["value"]; ok {
+			results = append(results, value)
+		}
+	}
+
+	return results, nil
+}
+
+// exampleStruct parses examples, found at pointer, with a Parser. Several objects are merged into a single struct, the
+// same way NDJSON values are; otherwise the first example is used.
+func (o *OpenAPIParser) exampleStruct(pointer string, examples []any) (*JSONStruct, error) {
+	opts := *o.ParserOptions
+	opts.Lenient = false
+	opts.NDJSON = len(examples) > 1
+
+	for _, example := range examples {
+		if _, ok := example.(*schemaObject); !ok {
+			opts.NDJSON = false
+		}
+	}
+
+	if !opts.NDJSON {
+		examples = examples[:1]
+	}
+
+	var input bytes.Buffer
+
+	encoder := json.NewEncoder(&input)
+	encoder.SetEscapeHTML(false)
+
+	for _, example := range examples {
+		if err := encoder.Encode(example); err != nil {
+			return nil, fmt.Errorf("%s: failed to encode example: %w", pointer, err)
+		}
+	}
+
+	parser, err := NewParserWithOptions(&input, o.log, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up parser: %w", err)
+	}
+
+	structs, err := parser.Start()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to parse example: %w", pointer, err)
+	}
+
+	js := structs[0]
+
+	// the elements of an array of objects are merged into a struct, but the body is the array itself
+	if _, ok := examples[0].([]any); ok && !js.IsNamedType() {
+		return newNamedType(NewField().SetValue([]any{js.SetInSlice()})), nil
+	}
+
+	return js, nil
+}
+
+// resolve follows the $ref of value, which is found at pointer, if it has one, and returns the object it refers to.
+func (o *OpenAPIParser) resolve(pointer string, value any) (*schemaObject, error) {
+	seen := map[string]bool{}
+
+	for {
+		obj, ok := value.(*schemaObject)
+		if !ok {
+			return nil, fmt.Errorf("%s: expecting an object, got %T: %w", pointer, value, ErrInvalidOpenAPI)
+		}
+
+		ref, ok := obj.str("$ref")
+		if !ok {
+			return obj, nil
+		}
+
+		refPointer, ok := strings.CutPrefix(ref, "#")
+		if !ok || seen[ref] {
+			return nil, fmt.Errorf("%s: unsupported $ref %q: %w", pointer, ref, ErrInvalidOpenAPI)
+		}
+
+		seen[ref] = true
+
+		target, err := o.schemas.resolve(refPointer)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to resolve $ref %q: %w", pointer, ref, err)
+		}
+
+		pointer, value = ref, target
+	}
+}
@@ -0,0 +1,171 @@
+package jsonstruct_test
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestOpenAPIParser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     *jsonstruct.OpenAPIOptions
+		expected string
+	}{
+		{
+			name: "json_schemas",
+			input: `{"openapi": "3.1.0", "paths": {"/users/{id}": {"get": {"responses": {
+				"200": {"content": {"application/json": {"schema": {"type": "object", "required": ["id"],
+					"properties": {"id": {"type": "integer"}, "name": {"type": "string"}}}}}},
+				"204": {"description": "no content"}
+			}}}}}`,
+			expected: "type GetUsersID200Response struct {\n\tID   int64  `json:\"id\"`\n" +
+				"\tName string `json:\"name,omitempty\"`\n}",
+		},
+		{
+			name: "yaml_components",
+			input: `openapi: 3.0.3
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        "200":
+          content:
+            application/json:
+              schema: {type: array, items: {$ref: '#/components/schemas/User'}}
+    post:
+      operationId: createUser
+      requestBody:
+        $ref: '#/components/requestBodies/User'
+      responses:
+        default:
+          content:
+            application/problem+json:
+              schema: {type: object, properties: {message: {type: string, nullable: true}}}
+components:
+  requestBodies:
+    User:
+      content:
+        application/json:
+          schema: {$ref: '#/components/schemas/User'}
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id: {type: integer}
+`,
+			expected: "type ListUsers200Response []*User\n\ntype User struct {\n\tID int64 `json:\"id\"`\n}\n\n" +
+				"type CreateUserRequest = User\n\ntype CreateUserDefaultResponse struct {\n" +
+				"\tMessage *string `json:\"message,omitempty\"`\n}",
+		},
+		{
+			name: "examples",
+			input: `openapi: 3.0.3
+paths:
+  /users:
+    post:
+      operationId: createUser
+      requestBody:
+        content:
+          text/plain: {example: hello}
+          application/json:
+            examples:
+              first: {value: {name: a}}
+              second: {value: {name: b, admin: true}}
+      responses:
+        "201":
+          content:
+            application/json:
+              example: [{id: 1}]
+              schema: {type: array, items: {type: string}}
+`,
+			expected: "type CreateUserRequest struct {\n\tName  string `json:\"name\"`\n" +
+				"\tAdmin bool   `json:\"admin,omitempty\"`\n}\n\ntype CreateUser201Response []*CreateUser201ResponseValue" +
+				"\n\ntype CreateUser201ResponseValue struct {\n\tID int64 `json:\"id\"`\n}",
+		},
+		{
+			name: "prefer_schemas",
+			input: `{"openapi": "3.0.0", "paths": {"/ping": {"get": {"operationId": "ping", "responses": {"200": {
+				"content": {"application/json": {"example": {"ok": true}, "schema": {"type": "string"}}}
+			}}}}}}`,
+			opts: &jsonstruct.OpenAPIOptions{
+				ParserOptions: &jsonstruct.ParserOptions{},
+				PreferSchemas: true,
+			},
+			expected: "type Ping200Response string",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := test.opts
+			if opts == nil {
+				opts = &jsonstruct.OpenAPIOptions{ParserOptions: &jsonstruct.ParserOptions{}}
+			}
+
+			parser, err := jsonstruct.NewOpenAPIParser(strings.NewReader(test.input), slog.Default(), opts)
+			assert.Nil(t, err)
+
+			structs, err := parser.Start()
+			assert.Nil(t, err)
+
+			formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{})
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs...)
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(output))
+		})
+	}
+}
+
+func TestOpenAPIParserErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{"swagger", `{"swagger": "2.0", "paths": {}}`, jsonstruct.ErrInvalidOpenAPI},
+		{"not_an_object", `[1, 2]`, jsonstruct.ErrInvalidOpenAPI},
+		{
+			"missing_ref", `{"openapi": "3.0.0", "paths": {"/": {"$ref": "#/components/pathItems/missing"}}}`,
+			jsonstruct.ErrInvalidSchema,
+		},
+		{"external_ref", `{"openapi": "3.0.0", "paths": {"/": {"get": {"responses": {
+			"200": {"$ref": "responses.yaml#/ok"}
+		}}}}}`, jsonstruct.ErrInvalidOpenAPI},
+		{"invalid_schema", `{"openapi": "3.0.0", "paths": {"/": {"get": {"responses": {
+			"200": {"content": {"application/json": {"schema": {"type": "thing"}}}}
+		}}}}}`, jsonstruct.ErrInvalidSchema},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser, err := jsonstruct.NewOpenAPIParser(strings.NewReader(test.input), slog.Default(),
+				&jsonstruct.OpenAPIOptions{ParserOptions: &jsonstruct.ParserOptions{}})
+			assert.Nil(t, err)
+
+			_, err = parser.Start()
+			assert.True(t, errors.Is(err, test.expected))
+		})
+	}
+}
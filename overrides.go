@@ -0,0 +1,393 @@
+package jsonstruct
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Override changes how the fields selected by Path or Key are rendered by the Formatter, when inference gets them wrong
+// for a domain: e.g. a "price" that should be a decimal.Decimal, or a "meta" object that should stay a json.RawMessage.
+type Override struct {
+	// Path selects a single field by its JSON path from the top-level value, e.g. "$.orders[*].price". Array indices and
+	// map keys are written as wildcards ("[*]" or ".*"), and the elements of a top-level array are the top-level values.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Key selects every field with this key, wherever it is. Overrides selecting a field by Path take precedence.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+
+	// Type is the Go type of the field, used as is. Like in FormatterOptions.FormatGoTypes, it can name its package by
+	// import path, e.g. "github.com/shopspring/decimal.Decimal". Structs that were inferred for the field aren't declared.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Name is the name of the Go field.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Tag replaces the struct tag of the field, e.g. `json:"price" db:"price_cents"`.
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+
+	// OmitEmpty marks the field as optional, adding ",omitempty" to its tags.
+	OmitEmpty bool `json:"omitempty,omitempty" yaml:"omitempty,omitempty"`
+
+	// Required marks the field as always present and never null.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+
+	// Skip leaves the field out of its struct.
+	Skip bool `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+// OK ensures that the override is valid.
+func (o *Override) OK() error {
+	if (o.Path == "") == (o.Key == "") {
+		return fmt.Errorf("overrides need either a path or a key")
+	}
+
+	if o.Path != "" {
+		if _, err := parsePath(o.Path); err != nil {
+			return err
+		}
+	}
+
+	if !o.Skip && o.Type == "" && o.Name == "" && o.Tag == "" && !o.OmitEmpty && !o.Required {
+		return fmt.Errorf("override for %s doesn't change anything", o.selector())
+	}
+
+	if o.Type != "" {
+		if _, _, _, err := qualifyType(o.Type); err != nil {
+			return fmt.Errorf("invalid override for %s: %w", o.selector(), err)
+		}
+	}
+
+	if o.Name != "" && (!token.IsIdentifier(o.Name) || !token.IsExported(o.Name)) {
+		return fmt.Errorf("invalid field name %q for %s", o.Name, o.selector())
+	}
+
+	if o.Tag != "" && !isStructTag(o.Tag) {
+		return fmt.Errorf("invalid tag %q for %s, expecting key:\"value\" pairs", o.Tag, o.selector())
+	}
+
+	if o.OmitEmpty && o.Required {
+		return fmt.Errorf("override for %s can't make the field both optional and required", o.selector())
+	}
+
+	return nil
+}
+
+// selector describes the fields selected by the override in errors.
+func (o *Override) selector() string {
+	if o.Path != "" {
+		return fmt.Sprintf("path %q", o.Path)
+	}
+
+	return fmt.Sprintf("key %q", o.Key)
+}
+
+// isStructTag returns true if tag is made of key:"value" pairs separated by spaces, as described in reflect.StructTag,
+// and can be rendered between backquotes.
+func isStructTag(tag string) bool {
+	if strings.ContainsRune(tag, '`') {
+		return false
+	}
+
+	for tag = strings.TrimSpace(tag); tag != ""; tag = strings.TrimLeft(tag, " ") {
+		// same rules as reflect.StructTag.Lookup
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return false
+		}
+
+		tag = tag[i+1:]
+
+		// the quoted value, which can contain escaped quotes
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+
+			i++
+		}
+
+		if i >= len(tag) {
+			return false
+		}
+
+		if _, err := strconv.Unquote(tag[:i+1]); err != nil {
+			return false
+		}
+
+		tag = tag[i+1:]
+		if tag != "" && tag[0] != ' ' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseOverrides decodes a list of Overrides from YAML or JSON, keyed like their json tags, e.g. `[{"path":
+// "$.orders[*].price", "type": "github.com/shopspring/decimal.Decimal"}]`. Unknown keys are rejected.
+func ParseOverrides(data []byte) ([]Override, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	results := []Override{}
+
+	if err := decoder.Decode(&results); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode overrides: %w", err)
+	}
+
+	for i := range results {
+		if err := results[i].OK(); err != nil {
+			return nil, fmt.Errorf("invalid override %d: %w", i, err)
+		}
+	}
+
+	return results, nil
+}
+
+// parsePath returns the canonical form of a JSON path selecting a field, made of pathKey segments and "[*]" wildcards,
+// e.g. `$.orders[*].price` for `$['orders'][0].price`. Leading wildcards are dropped, since the elements of a top-level
+// array are the top-level values.
+func parsePath(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, rootKey)
+	if !ok {
+		return "", fmt.Errorf("invalid path %q, expecting it to start with %q", path, rootKey)
+	}
+
+	segments := []string{rootKey}
+
+	for rest != "" {
+		var segment string
+
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			return "", fmt.Errorf("invalid path %q: recursive descent isn't supported, use a key instead", path)
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+
+			segment, rest = rest[1:end], rest[end:]
+		case rest[0] == '[' && len(rest) > 1 && (rest[1] == '\'' || rest[1] == '"'):
+			// quoted keys, e.g. ['some key']
+			end := strings.IndexByte(rest[2:], rest[1]) + 2
+			if end == 1 || !strings.HasPrefix(rest[end+1:], "]") {
+				return "", fmt.Errorf("invalid path %q: unterminated key", path)
+			}
+
+			segments = append(segments, pathKey(rest[2:end]))
+			rest = rest[end+2:]
+
+			continue
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return "", fmt.Errorf("invalid path %q: unterminated index", path)
+			}
+
+			segment, rest = rest[1:end], rest[end+1:]
+			isIndex := segment != "" && strings.IndexFunc(segment, func(r rune) bool { return !isNumber(r) }) == -1
+			if segment != "*" && !isIndex {
+				return "", fmt.Errorf("invalid path %q: invalid index %q", path, segment)
+			}
+
+			// indices select every element, since the elements of a slice are merged
+			segment = "*"
+		default:
+			return "", fmt.Errorf("invalid path %q: unexpected %q", path, rest[:1])
+		}
+
+		switch {
+		case segment == "":
+			return "", fmt.Errorf("invalid path %q: empty key", path)
+		case segment != "*":
+			segments = append(segments, pathKey(segment))
+		case len(segments) > 1:
+			segments = append(segments, "[*]")
+		}
+	}
+
+	if last := segments[len(segments)-1]; last == rootKey || last == "[*]" {
+		return "", fmt.Errorf("invalid path %q: it doesn't select a field", path)
+	}
+
+	return strings.Join(segments, ""), nil
+}
+
+// structPath returns the path of the struct held by field, which is at path: e.g. "$.orders[*]" for a slice of structs
+// at "$.orders". The elements of slices and the values of maps are both written as "[*]".
+func structPath(field *Field, path string) string {
+	for field != nil && !field.IsStruct() {
+		path += "[*]"
+
+		if field.IsSlice() {
+			field = field.SliceElementField()
+		} else {
+			field = field.MapValueField()
+		}
+	}
+
+	return path
+}
+
+// applyOverrides applies the Overrides in the FormatterOptions and those set on the Parsers of inputs to the fields of
+// inputs and of the structs nested within them. Overrides selecting fields by key are applied first, then those
+// selecting them by path, in order. The import paths of the types they set are recorded for file mode, and the
+// overrides that don't select any field for UnmatchedOverrides.
+func (f *Formatter) applyOverrides(inputs []*JSONStruct) error {
+	f.imports = map[string]string{}
+	f.matched = map[Override]bool{}
+	f.unmatched = []string{}
+	visited := map[*JSONStruct]bool{}
+	all := []Override{}
+
+	var visit func(js *JSONStruct, path string, overrides []Override) error
+
+	visit = func(js *JSONStruct, path string, overrides []Override) error {
+		if visited[js] {
+			return nil
+		}
+
+		visited[js] = true
+
+		if js.value != nil {
+			if js.value.HasStruct() {
+				return visit(js.value.GetStruct(), structPath(js.value, path), overrides)
+			}
+
+			return nil
+		}
+
+		fields := Fields{}
+
+		for _, field := range js.fields {
+			fieldPath := path + pathKey(field.OriginalName())
+
+			skip, err := f.applyFieldOverrides(field, fieldPath, overrides)
+			if err != nil {
+				return err
+			}
+
+			if skip {
+				continue
+			}
+
+			fields = append(fields, field)
+
+			if field.HasStruct() {
+				if err := visit(field.GetStruct(), structPath(field, fieldPath), overrides); err != nil {
+					return err
+				}
+			}
+		}
+
+		js.fields = fields
+
+		return nil
+	}
+
+	for _, input := range inputs {
+		overrides := append(append([]Override{}, f.Overrides...), input.overrides...)
+		if len(overrides) == 0 {
+			continue
+		}
+
+		if err := visit(input, rootKey, overrides); err != nil {
+			return err
+		}
+
+		all = append(all, overrides...)
+	}
+
+	for _, override := range all {
+		if selector := override.selector(); !f.matched[override] && !slices.Contains(f.unmatched, selector) {
+			f.unmatched = append(f.unmatched, selector)
+		}
+	}
+
+	return nil
+}
+
+// UnmatchedOverrides describes the Overrides that didn't select any field in the last call to FormatStructs, e.g.
+// `path "$.orders[*].price"`, which likely means that their path or key has a typo.
+func (f *Formatter) UnmatchedOverrides() []string {
+	return f.unmatched
+}
+
+// applyFieldOverrides applies the overrides selecting field, which is at path, and returns true if it is skipped.
+func (f *Formatter) applyFieldOverrides(field *Field, path string, overrides []Override) (bool, error) {
+	matches := []Override{}
+
+	for _, override := range overrides {
+		if override.Key == field.OriginalName() {
+			matches = append(matches, override)
+		}
+	}
+
+	for _, override := range overrides {
+		if override.Path != "" {
+			if overridePath, _ := parsePath(override.Path); overridePath == path {
+				matches = append(matches, override)
+			}
+		}
+	}
+
+	for _, override := range matches {
+		f.matched[override] = true
+	}
+
+	for _, override := range matches {
+		if override.Skip {
+			return true, nil
+		}
+
+		if override.Type != "" {
+			typ, qualifier, importPath, err := qualifyType(override.Type)
+			if err != nil {
+				return false, fmt.Errorf("invalid override for %s: %w", override.selector(), err)
+			}
+
+			if importPath != "" {
+				if existing, ok := f.imports[qualifier]; ok && existing != importPath {
+					return false, fmt.Errorf("package name %q of %q is already used by %q", qualifier, importPath,
+						existing)
+				}
+
+				f.imports[qualifier] = importPath
+			}
+
+			field.goType = typ
+		}
+
+		if override.Name != "" {
+			field.goName = override.Name
+		}
+
+		if override.Tag != "" {
+			field.tag = override.Tag
+		}
+
+		if override.OmitEmpty {
+			field.optional = true
+		}
+
+		if override.Required {
+			field.optional, field.nullable = false, false
+		}
+	}
+
+	return false, nil
+}
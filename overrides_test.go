@@ -0,0 +1,179 @@
+package jsonstruct_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestFormatterOverrides(t *testing.T) {
+	t.Parallel()
+
+	input := `{"orders": [{"id": 1, "price": "9.99", "meta": {"a": 1}, "note": "x"}, ` +
+		`{"id": null, "price": "1.5", "meta": {"b": 2}}], "meta": {"c": 3}, "prices": {"usd": {"amount": 1}}}`
+
+	tests := []struct {
+		name            string
+		overrides       []jsonstruct.Override
+		parserOverrides []jsonstruct.Override
+		expected        string
+		unmatched       []string
+	}{
+		{
+			name: "type_by_path",
+			overrides: []jsonstruct.Override{
+				{Path: "$.orders[*].price", Type: "github.com/shopspring/decimal.Decimal"},
+				{Path: "$['prices'].*.amount", Type: "float64"},
+			},
+			expected: "import \"github.com/shopspring/decimal\"\n\ntype Root struct {\n" +
+				"\tOrders []*Orders          `json:\"orders\"`\n\tMeta   *RootMeta          `json:\"meta\"`\n" +
+				"\tPrices map[string]*Prices `json:\"prices\"`\n}\n\n" +
+				"type Orders struct {\n\tID    *int64          `json:\"id\"`\n\tPrice decimal.Decimal `json:\"price\"`\n" +
+				"\tMeta  *OrdersMeta     `json:\"meta\"`\n\tNote  string          `json:\"note,omitempty\"`\n}\n\n" +
+				"type OrdersMeta struct {\n\tA int64 `json:\"a,omitempty\"`\n\tB int64 `json:\"b,omitempty\"`\n}\n\n" +
+				"type RootMeta struct {\n\tC int64 `json:\"c\"`\n}\n\n" +
+				"type Prices struct {\n\tAmount float64 `json:\"amount\"`\n}",
+		},
+		{
+			name: "key",
+			overrides: []jsonstruct.Override{
+				{Key: "meta", Type: "json.RawMessage"},
+				{Key: "prices", Skip: true},
+			},
+			expected: "import \"encoding/json\"\n\ntype Root struct {\n" +
+				"\tOrders []*Orders       `json:\"orders\"`\n\tMeta   json.RawMessage `json:\"meta\"`\n}\n\n" +
+				"type Orders struct {\n\tID    *int64          `json:\"id\"`\n\tPrice string          `json:\"price\"`\n" +
+				"\tMeta  json.RawMessage `json:\"meta\"`\n\tNote  string          `json:\"note,omitempty\"`\n}",
+		},
+		{
+			name: "path_over_key",
+			overrides: []jsonstruct.Override{
+				{Path: "$.orders[0].id", Name: "OrderID", Required: true},
+				{Key: "id", Name: "Identifier"},
+				{Path: "$.orders[*].note", Tag: `json:"note" db:"note_text"`},
+				{Path: "$.orders[*].price", OmitEmpty: true},
+				{Key: "meta", Skip: true},
+				{Key: "prices", Skip: true},
+			},
+			expected: "type Root struct {\n\tOrders []*Orders `json:\"orders\"`\n}\n\n" +
+				"type Orders struct {\n\tOrderID int64  `json:\"id\"`\n\tPrice   string `json:\"price,omitempty\"`\n" +
+				"\tNote    string `json:\"note\" db:\"note_text\"`\n}",
+		},
+		{
+			name:            "parser",
+			overrides:       []jsonstruct.Override{{Key: "orders", Skip: true}, {Key: "meta", Type: "any"}},
+			parserOverrides: []jsonstruct.Override{{Path: "$.meta", Type: "map[string]int"}, {Key: "prices", Skip: true}},
+			expected:        "type Root struct {\n\tMeta map[string]int `json:\"meta\"`\n}",
+		},
+		{
+			name: "unmatched",
+			overrides: []jsonstruct.Override{
+				{Key: "orders", Skip: true},
+				{Path: "$.orders[*].price", Type: "float64"},
+				{Key: "nothing", Skip: true},
+				{Key: "prices", Skip: true},
+			},
+			parserOverrides: []jsonstruct.Override{{Path: "$.meta.d", Type: "string"}, {Key: "nothing", Name: "Nothing"}},
+			expected: "type Root struct {\n\tMeta *Meta `json:\"meta\"`\n}\n\n" +
+				"type Meta struct {\n\tC int64 `json:\"c\"`\n}",
+			unmatched: []string{`path "$.orders[*].price"`, `key "nothing"`, `path "$.meta.d"`},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(input), slog.Default(),
+				&jsonstruct.ParserOptions{MapKeys: []string{"prices"}, Overrides: test.parserOverrides})
+			assert.Nil(t, err)
+
+			structs, err := parser.Start()
+			assert.Nil(t, err)
+
+			formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{
+				PackageName: "models",
+				Overrides:   test.overrides,
+			})
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs[0].SetName("Root"))
+			assert.Nil(t, err)
+			assert.Equal(t, "package models\n\n"+test.expected+"\n", output)
+			assert.ElementsMatch(t, test.unmatched, formatter.UnmatchedOverrides())
+		})
+	}
+}
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestParseOverrides(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []jsonstruct.Override
+		valid    bool
+	}{
+		{
+			name: "yaml",
+			input: "- path: $.orders[*].price\n  type: github.com/shopspring/decimal.Decimal\n" +
+				"- key: meta\n  skip: true\n",
+			expected: []jsonstruct.Override{
+				{Path: "$.orders[*].price", Type: "github.com/shopspring/decimal.Decimal"},
+				{Key: "meta", Skip: true},
+			},
+			valid: true,
+		},
+		{
+			name:     "json",
+			input:    `[{"key": "id", "name": "OrderID", "tag": "json:\"id\" db:\"order_id\"", "required": true}]`,
+			expected: []jsonstruct.Override{{Key: "id", Name: "OrderID", Tag: `json:"id" db:"order_id"`, Required: true}},
+			valid:    true,
+		},
+		{name: "empty", input: "", expected: []jsonstruct.Override{}, valid: true},
+		{name: "unknown_field", input: "- key: id\n  typ: int\n"},
+		{name: "no_selector", input: "- type: int\n"},
+		{name: "both_selectors", input: "- key: id\n  path: $.id\n  type: int\n"},
+		{name: "no_change", input: "- key: id\n"},
+		{name: "relative_path", input: "- path: orders.id\n  skip: true\n"},
+		{name: "recursive_descent", input: "- path: $..id\n  skip: true\n"},
+		{name: "wildcard_path", input: "- path: $.orders[*]\n  skip: true\n"},
+		{name: "root_path", input: "- path: $\n  skip: true\n"},
+		{name: "invalid_index", input: "- path: $.orders[x].id\n  skip: true\n"},
+		{name: "unterminated_key", input: "- path: $['orders\n  skip: true\n"},
+		{name: "invalid_type", input: "- key: id\n  type: not a type\n"},
+		{name: "invalid_name", input: "- key: id\n  name: orderID\n"},
+		{name: "invalid_tag", input: "- key: id\n  tag: 'json:id'\n"},
+		{name: "optional_and_required", input: "- key: id\n  omitempty: true\n  required: true\n"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			overrides, err := jsonstruct.ParseOverrides([]byte(test.input))
+			if !test.valid {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, overrides)
+		})
+	}
+
+	_, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{Overrides: []jsonstruct.Override{{Key: "id"}}})
+	assert.NotNil(t, err)
+
+	_, err = jsonstruct.NewParserWithOptions(strings.NewReader("{}"), slog.Default(),
+		&jsonstruct.ParserOptions{Overrides: []jsonstruct.Override{{Path: "id", Skip: true}}})
+	assert.NotNil(t, err)
+}
@@ -7,68 +7,200 @@ import (
 	"io"
 	"log/slog"
 	"math/big"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 var ErrOverflow = errors.New("provided number was too large")
 
+// unexpectedEnd is the message of the json.SyntaxError returned when the input ends in the middle of a value.
+const unexpectedEnd = "unexpected end of JSON input"
+
+// rootKey refers to the top-level value in ParserOptions.MapKeys.
+const rootKey = "$"
+
+// ParserOptions defines how the Parser will interpret its input.
+type ParserOptions struct {
+	// InferTime types string values that look like RFC 3339 timestamps as time.Time rather than string. Dates get the
+	// "date" format, typed as the Date type declared by the Formatter.
+	InferTime bool
+
+	// InferFormats recognizes well-known formats in string values with the DefaultClassifiers: UUIDs, URLs, IP
+	// addresses, emails, hostnames, Go durations, and base64. Fields holding some of them get another type (e.g.
+	// netip.Addr for IP addresses, see FormatterOptions.FormatGoTypes), and string fields get a validate rule.
+	InferFormats bool
+
+	// Classifiers recognize custom formats in string values. They are tried in order, before the DefaultClassifiers.
+	Classifiers []StringClassifier
+
+	// InferNumbers types string fields that hold an integer or a float in every sample as int64 or float64, with the
+	// ",string" option in their json tag, e.g. `json:"id,string"`. A single sample that isn't a number keeps the field a
+	// string. It has no effect on YAML and TOML input.
+	InferNumbers bool
+
+	// DetectMaps renders objects whose keys look like data (numeric IDs, UUIDs, dates, hostnames...) rather than field
+	// names as map[string]T instead of structs, where T is inferred by merging their values.
+	DetectMaps bool
+
+	// MapKeys lists keys whose object values are always rendered as maps, regardless of DetectMaps. Use "$" for the
+	// top-level value.
+	MapKeys []string
+
+	// NDJSON treats every top-level value as a sample of the same type, e.g. the lines of a log file, and merges them
+	// into a single JSONStruct. The samples are merged as they are read, so the input doesn't have to fit in memory.
+	NDJSON bool
+
+	// Lenient accepts JSONC and JSON5 input: comments, trailing commas, unquoted keys, single-quoted strings, and JSON5
+	// numbers. Comments attached to keys become the doc comments of the corresponding fields.
+	Lenient bool
+
+	// InputFormat is the format of the input: JSON by default, or YAML / TOML. Fields parsed from YAML or TOML are
+	// tagged for that format, e.g. `yaml:"name"`. Lenient only applies to JSON.
+	InputFormat InputFormat
+
+	// Overrides are set on the JSONStructs returned by the Parser, and applied by the Formatter after its own.
+	Overrides []Override
+}
+
+// OK ensures that the options passed in are valid.
+func (p *ParserOptions) OK() error {
+	switch p.InputFormat {
+	case "", InputJSON, InputYAML, InputTOML:
+	default:
+		return fmt.Errorf("invalid input format %q", p.InputFormat)
+	}
+
+	for i := range p.Overrides {
+		if err := p.Overrides[i].OK(); err != nil {
+			return fmt.Errorf("invalid override %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
 type Parser struct {
-	log      *slog.Logger
+	*ParserOptions
+
+	log *slog.Logger
+	// classifiers are the StringClassifiers enabled in the ParserOptions
+	classifiers []StringClassifier
+	// document is the input of YAML and TOML parsers, which is decoded all at once rather than token by token
+	document io.Reader
 	decoder  *json.Decoder
+	lenient  *lenientReader
+	position *positionReader
+	// path contains the segments of the JSON path of the value being parsed, e.g. ["$", ".structs", "[2]"]
+	path     []string
 	current  any
 	previous any
 	buf      any
+	// buffered is true if buf holds a token, which can be nil for JSON null
+	buffered bool
 	started  bool
 }
 
+// NewParser returns a Parser with the default options.
 func NewParser(input io.Reader, logger *slog.Logger) *Parser {
-	decoder := json.NewDecoder(input)
-	decoder.UseNumber()
+	parser, _ := NewParserWithOptions(input, logger, &ParserOptions{})
+
+	return parser
+}
+
+// NewParserWithOptions returns a Parser configured with opts.
+func NewParserWithOptions(input io.Reader, logger *slog.Logger, opts *ParserOptions) (*Parser, error) {
+	if err := opts.OK(); err != nil {
+		return nil, fmt.Errorf("invalid parser options: %w", err)
+	}
+
+	parser := &Parser{
+		ParserOptions: opts,
+		log:           logger,
+		classifiers:   opts.enabledClassifiers(),
+	}
+
+	if opts.isDocumentFormat() {
+		parser.document = input
+
+		return parser, nil
+	}
 
-	return &Parser{
-		decoder: decoder,
-		log:     logger,
+	if opts.Lenient {
+		parser.lenient = newLenientReader(input)
+		input = parser.lenient
 	}
+
+	parser.position = newPositionReader(input)
+	parser.decoder = json.NewDecoder(parser.position)
+	parser.decoder.UseNumber()
+
+	return parser, nil
 }
 
+// Start parses the input and returns a JSONStruct for every top-level value, or a single one for NDJSON input.
 func (p *Parser) Start() (JSONStructs, error) {
+	results, err := p.start()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, js := range results {
+		js.overrides = p.Overrides
+	}
+
+	return results, nil
+}
+
+func (p *Parser) start() (JSONStructs, error) {
+	if p.document != nil {
+		return p.startDocuments()
+	}
+
+	if p.NDJSON {
+		return p.startNDJSON()
+	}
+
 	results := JSONStructs{}
 
 	for i := 0; ; i++ {
+		p.path = []string{rootKey}
+
 		first, err := p.peek()
 		if errors.Is(err, io.EOF) {
 			break
 		} else if err != nil {
-			return nil, fmt.Errorf("failed to start parser: %w", err)
+			return nil, p.parseError(fmt.Errorf("failed to start parser: %w", err))
 		}
 
-		p.log.Debug("successfully read first token")
+		p.log.Debug("successfully read first token", "token", first)
 
-		delim, ok := first.(json.Delim)
-		if !ok {
-			return nil, fmt.Errorf("expecting to start with a json.Delim, got %+v", first)
-		}
-
-		p.log.Debug("successfully read a JSON delimiter", "delim", delim)
-
-		switch delim {
-		case '{':
+		switch first {
+		case json.Delim('{'):
 			js, err := p.parseObject()
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse object: %w", err)
+				return nil, p.parseError(fmt.Errorf("failed to parse object: %w", err))
 			}
 
-			results = append(results, js)
-		case '[':
+			results = append(results, p.rootStruct(js))
+		case json.Delim('['):
 			jsRaw, err := p.parseArray()
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse array: %w", err)
+				return nil, p.parseError(fmt.Errorf("failed to parse array: %w", err))
+			}
+
+			results = append(results, p.rootStruct(jsRaw))
+		default:
+			if err := p.next(); err != nil {
+				return nil, p.parseError(err)
 			}
 
-			js := getSliceStruct(jsRaw)
+			value, err := p.parseCurrent()
+			if err != nil {
+				return nil, p.parseError(fmt.Errorf("failed to parse value: %w", err))
+			}
 
-			results = append(results, js)
+			results = append(results, p.rootStruct(value))
 		}
 	}
 
@@ -76,14 +208,85 @@ func (p *Parser) Start() (JSONStructs, error) {
 	return results, nil
 }
 
+// rootStruct returns the JSONStruct for a top-level value: a struct for an object, unless it's treated as a map, the
+// merged struct for an array of objects, and a named type for anything else, e.g. "type Stdin1 string".
+func (p *Parser) rootStruct(value any) *JSONStruct {
+	switch val := value.(type) {
+	case *JSONStruct:
+		if jMap, ok := p.mapOrStruct(rootKey, val).(*jsonMap); ok {
+			return newNamedType(NewField().SetValue(jMap))
+		}
+
+		return val
+	case []any:
+		return arrayStruct(val)
+	}
+
+	return newNamedType(NewField().SetValue(plainString(value)))
+}
+
+// parseError wraps err in a ParseError describing where the Parser was in the input, unless it already is one.
+func (p *Parser) parseError(err error) error {
+	// e.g. errors found in lenient mode, which have to point to the original input
+	parseErr := &ParseError{}
+	if errors.As(err, &parseErr) {
+		return parseErr
+	}
+
+	offset := p.decoder.InputOffset()
+
+	// syntax errors are found after reading the offending character, except for the end of the input
+	syntaxErr := &json.SyntaxError{}
+
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &syntaxErr) && syntaxErr.Error() == unexpectedEnd:
+		offset = p.position.end()
+	case errors.As(err, &syntaxErr) && syntaxErr.Offset > 0:
+		offset = syntaxErr.Offset - 1
+	}
+
+	result := p.position.parseError(offset, strings.Join(p.path, ""), err)
+
+	// the Parser reads the converted input in lenient mode, but the snippet should show the original
+	if p.lenient != nil {
+		p.lenient.originalSnippet(result)
+	}
+
+	return result
+}
+
+// pushPath adds segment to the JSON path of the value being parsed. It is removed by popPath once the value has been
+// parsed successfully, so that the path still points to the value in ParseErrors.
+func (p *Parser) pushPath(segment string) {
+	p.path = append(p.path, segment)
+}
+
+func (p *Parser) popPath() {
+	p.path = p.path[:len(p.path)-1]
+}
+
+// pathKey returns the JSON path segment for key: ".key" if it's an identifier, or `["some key"]` otherwise.
+func pathKey(key string) string {
+	isIdentifier := key != "" && !isNumber(rune(key[0])) && strings.IndexFunc(key, func(r rune) bool {
+		return !isAlphaNum(r) && r != '_'
+	}) == -1
+
+	if isIdentifier {
+		return "." + key
+	}
+
+	return "[" + strconv.Quote(key) + "]"
+}
+
 func (p *Parser) next() error {
 	p.started = true
 
 	// we have a previously-buffered token
-	if p.buf != nil {
+	if p.buffered {
 		p.previous = p.current
 		p.current = p.buf
 		p.buf = nil
+		p.buffered = false
 
 		p.log.Debug("setting current token to buffered token", "buf", p.current)
 
@@ -110,6 +313,7 @@ func (p *Parser) backup() error {
 
 	// buffer the current token, pick it up with the subsequent next() call, back current up to previous
 	p.buf, p.current = p.current, p.previous
+	p.buffered = true
 
 	p.log.Debug("putting current token in buffer, setting current to previous token", "current", p.current, "buf", p.buf)
 
@@ -153,12 +357,22 @@ func (p *Parser) parseObject() (*JSONStruct, error) {
 
 		p.log.Debug("parsed key", "key", key)
 
+		doc := p.keyComment()
+
+		p.pushPath(pathKey(key))
+
 		val, err := p.parseValue()
 		if err != nil {
 			return result, fmt.Errorf("failed to parse value: %w", err)
 		}
 
-		field := (&Field{}).SetName(key).SetValue(val)
+		p.popPath()
+
+		if js, ok := val.(*JSONStruct); ok {
+			val = p.mapOrStruct(key, js)
+		}
+
+		field := (&Field{}).SetName(key).SetValue(val).SetDoc(doc)
 
 		result.AddFields(field)
 	}
@@ -174,6 +388,42 @@ func (p *Parser) parseObject() (*JSONStruct, error) {
 	return result, nil
 }
 
+// keyComment returns the comment attached to the key that was just read in lenient mode, if any.
+func (p *Parser) keyComment() string {
+	if p.lenient == nil {
+		return ""
+	}
+
+	return p.lenient.comment(p.decoder.InputOffset())
+}
+
+// mapOrStruct returns js as a *jsonMap if it was found under one of the MapKeys or if DetectMaps is enabled and its
+// keys look like data, otherwise it returns js unchanged.
+func (p *Parser) mapOrStruct(key string, js *JSONStruct) any {
+	isMapKey := key != "" && slices.Contains(p.MapKeys, key)
+
+	if isMapKey || (p.DetectMaps && looksLikeMap(js)) {
+		p.log.Debug("treating object as a map", "key", key)
+
+		return newJSONMap(js)
+	}
+
+	return js
+}
+
+// arrayStruct returns the JSONStruct for a top-level array: the objects in an array of objects are merged into a single
+// struct, and anything else becomes a named slice type, e.g. "type Stdin1 []int64".
+func arrayStruct(values []any) *JSONStruct {
+	if isStructSlice(values) {
+		result := getSliceStruct(values)
+		result.fromArray = true
+
+		return result
+	}
+
+	return newNamedType(NewField().SetValue(values))
+}
+
 func (p *Parser) parseDelim(delim rune) error {
 	delimToken, ok := p.current.(json.Delim)
 	if !ok {
@@ -211,6 +461,45 @@ func (p *Parser) parseString() (string, error) {
 	return tokenStr, nil
 }
 
+// classifyString returns a formattedString if str is recognized as one of the formats enabled in the ParserOptions,
+// otherwise it returns str unchanged.
+func (p *Parser) classifyString(str string) any {
+	if format := p.stringFormat(str); format != "" {
+		p.log.Debug("got formatted string", "string", str, "format", format)
+
+		return formattedString{value: str, format: format}
+	}
+
+	return str
+}
+
+// stringFormat returns the first StringFormat recognized in str by the classifiers enabled in the ParserOptions, or an
+// empty string if there isn't one.
+func (p *Parser) stringFormat(str string) StringFormat {
+	return classifyFormat(str, p.classifiers)
+}
+
+// enabledClassifiers returns the classifiers enabled in the options, in the order they are tried: custom Classifiers
+// before the built-in ones.
+func (p *ParserOptions) enabledClassifiers() []StringClassifier {
+	classifiers := append([]StringClassifier{}, p.Classifiers...)
+
+	if p.InferTime {
+		classifiers = append(classifiers, timeFormat)
+	}
+
+	if p.InferFormats {
+		classifiers = append(classifiers, DefaultClassifiers...)
+	}
+
+	// YAML and TOML have no equivalent to the ",string" option
+	if p.InferNumbers && !p.isDocumentFormat() {
+		classifiers = append(classifiers, numberFormat)
+	}
+
+	return classifiers
+}
+
 func (p *Parser) parseNumber() (any, error) {
 	tokenNumber, ok := p.current.(json.Number)
 	if !ok {
@@ -318,11 +607,19 @@ func (p *Parser) parseArray() ([]any, error) {
 	}
 
 	for p.decoder.More() {
+		p.pushPath(fmt.Sprintf("[%d]", len(result)))
+
 		val, err := p.parseValue()
 		if err != nil {
 			return nil, err
 		}
 
+		p.popPath()
+
+		if js, ok := val.(*JSONStruct); ok {
+			val = p.mapOrStruct("", js)
+		}
+
 		result = append(result, val)
 	}
 
@@ -348,7 +645,12 @@ func (p *Parser) parseValue() (any, error) {
 
 	p.current = token
 
-	switch val := token.(type) {
+	return p.parseCurrent()
+}
+
+// parseCurrent parses the value starting with the current token.
+func (p *Parser) parseCurrent() (any, error) {
+	switch val := p.current.(type) {
 	case json.Delim:
 		p.log.Debug("got a delim, trying to back up to parse either object or array", "delim", val)
 
@@ -364,7 +666,12 @@ func (p *Parser) parseValue() (any, error) {
 	case bool:
 		return p.parseBool()
 	case string:
-		return p.parseString()
+		str, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+
+		return p.classifyString(str), nil
 	case json.Number:
 		return p.parseNumber()
 	default:
@@ -68,6 +68,27 @@ func TestParser(t *testing.T) {
 			),
 			errors: false,
 		},
+		{
+			name:  "mixed_number_array_of_objects",
+			input: `[{"test": 1}, {"test": 1.5}, {"test": 2}]`,
+			expected: jsonstruct.New().SetName("").AddFields(
+				jsonstruct.NewField().SetName("test").SetValue(float64(1)),
+			),
+			errors: false,
+		},
+		{
+			name:  "nested_array_of_objects",
+			input: `[{"test": {"a": 1}}, {"test": {"b": 2}}]`,
+			expected: jsonstruct.New().SetName("").AddFields(
+				jsonstruct.NewField().SetName("test").SetValue(
+					jsonstruct.New().SetName("").AddFields(
+						jsonstruct.NewField().SetName("a").SetValue(int64(1)).SetOptional(),
+						jsonstruct.NewField().SetName("b").SetValue(int64(2)).SetOptional(),
+					),
+				),
+			),
+			errors: false,
+		},
 		{
 			name:   "int_key",
 			input:  `{1: 2}`,
@@ -107,6 +128,249 @@ func TestParser(t *testing.T) {
 	}
 }
 
+func TestParserInferTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"rfc3339", `{"a": "2024-01-02T15:04:05Z"}`, []string{"time.Time"}},
+		{"rfc3339_nano", `{"a": "2024-01-02T15:04:05.999999999+02:00"}`, []string{"time.Time"}},
+		{"date", `{"a": "2024-01-02"}`, []string{"Date"}},
+		{"optional_date", `[{"a": "2024-01-02"}, {}]`, []string{"*Date"}},
+		{
+			name:     "not_time",
+			input:    `{"a": "2024-01-02 15:04:05", "b": "2024-13-45", "c": "abcd-efghij"}`,
+			expected: []string{"string", "string", "string"},
+		},
+		{"slice", `{"a": ["2024-01-02T15:04:05Z", "2024-01-03T15:04:05Z"]}`, []string{"[]time.Time"}},
+		{"dates", `{"a": ["2024-01-02", "2024-01-03"]}`, []string{"[]Date"}},
+		{"dates_and_timestamps", `{"a": ["2024-01-02", "2024-01-03T15:04:05Z"]}`, []string{"[]string"}},
+		{"mixed_slice", `{"a": ["2024-01-02", "abc"]}`, []string{"[]string"}},
+		{
+			name: "slice_of_structs",
+			input: `[{"a": "2024-01-02T15:04:05Z", "b": "2024-01-02T15:04:05Z", "c": "2024-01-02T15:04:05Z"}, ` +
+				`{"a": "2024-01-03T15:04:05Z", "b": "abc"}]`,
+			expected: []string{"time.Time", "string", "*time.Time"},
+		},
+		{
+			name:     "slice_of_structs_time_after_string",
+			input:    `[{"a": "abc"}, {"a": "2024-01-02T15:04:05Z"}]`,
+			expected: []string{"string"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := strings.NewReader(test.input)
+			p, err := jsonstruct.NewParserWithOptions(r, slog.Default(), &jsonstruct.ParserOptions{InferTime: true})
+			assert.Nil(t, err)
+
+			structs, err := p.Start()
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(structs))
+
+			types := []string{}
+			for _, field := range structs[0].Fields() {
+				types = append(types, field.Type())
+			}
+
+			assert.Equal(t, test.expected, types)
+		})
+	}
+}
+
+func TestParserInferNumbers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"int", `{"a": "1234567890123", "b": "-1"}`, []string{"int64 `json:\"a,string\"`", "int64 `json:\"b,string\"`"}},
+		{"float", `{"a": "19.99", "b": "1e3"}`, []string{"float64 `json:\"a,string\"`", "float64 `json:\"b,string\"`"}},
+		{
+			name:  "not_numbers",
+			input: `{"a": "01234", "b": "+1", "c": " 1", "d": "1.", "e": "true", "f": "", "g": "99999999999999999999"}`,
+			expected: []string{
+				"string `json:\"a\"`", "string `json:\"b\"`", "string `json:\"c\"`", "string `json:\"d\"`",
+				"string `json:\"e\"`", "string `json:\"f\"`", "string `json:\"g\"`",
+			},
+		},
+		{"slice", `{"a": ["1", "2"]}`, []string{"[]string `json:\"a\"`"}},
+		{
+			name:  "slice_of_structs",
+			input: `[{"a": "1", "b": "1", "c": "1", "d": "1"}, {"a": "2", "b": "1.5", "c": "x", "d": null}, {"a": "3"}]`,
+			expected: []string{
+				"int64 `json:\"a,string\"`", "float64 `json:\"b,string,omitempty\"`", "string `json:\"c,omitempty\"`",
+				"*int64 `json:\"d,string,omitempty\"`",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := strings.NewReader(test.input)
+			p, err := jsonstruct.NewParserWithOptions(r, slog.Default(), &jsonstruct.ParserOptions{InferNumbers: true})
+			assert.Nil(t, err)
+
+			structs, err := p.Start()
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(structs))
+
+			types := []string{}
+			for _, field := range structs[0].Fields() {
+				types = append(types, strings.TrimSpace(field.Type()+" "+field.Tag()))
+			}
+
+			assert.Equal(t, test.expected, types)
+		})
+	}
+}
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestParserMaps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     *jsonstruct.ParserOptions
+		expected []string
+	}{
+		{
+			name:     "numeric_keys",
+			input:    `{"a": {"1": {"b": 1}, "2": {"b": 2, "c": "x"}}}`,
+			opts:     &jsonstruct.ParserOptions{DetectMaps: true},
+			expected: []string{"map[string]*A"},
+		},
+		{
+			name:     "uuid_keys",
+			input:    `{"a": {"6ba7b810-9dad-11d1-80b4-00c04fd430c8": 1.5}}`,
+			opts:     &jsonstruct.ParserOptions{DetectMaps: true},
+			expected: []string{"map[string]float64"},
+		},
+		{
+			name:     "date_and_hostname_keys",
+			input:    `{"a": {"2024-01-02": true}, "b": {"web-1.example.com": "up", "10.0.0.1": "down"}}`,
+			opts:     &jsonstruct.ParserOptions{DetectMaps: true},
+			expected: []string{"map[string]bool", "map[string]string"},
+		},
+		{
+			name:     "numbered_identifier_keys",
+			input:    `{"a": {"user_123": [1, 2], "user_456": [3]}}`,
+			opts:     &jsonstruct.ParserOptions{DetectMaps: true},
+			expected: []string{"map[string][]int64"},
+		},
+		{
+			name:     "nested_maps",
+			input:    `{"a": {"1": {"2024-01-02": "x"}, "2": {"2024-01-03": "y"}}}`,
+			opts:     &jsonstruct.ParserOptions{DetectMaps: true},
+			expected: []string{"map[string]map[string]string"},
+		},
+		{
+			name:     "field_names",
+			input:    `{"a": {"name": "x", "line_1": "y", "sha256": "z"}}`,
+			opts:     &jsonstruct.ParserOptions{DetectMaps: true},
+			expected: []string{"*A"},
+		},
+		{
+			name:     "incompatible_values",
+			input:    `{"a": {"1": 1, "2": "x"}}`,
+			opts:     &jsonstruct.ParserOptions{DetectMaps: true},
+			expected: []string{"*A"},
+		},
+		{
+			name:     "detection_disabled",
+			input:    `{"a": {"1": 1, "2": 2}}`,
+			opts:     &jsonstruct.ParserOptions{},
+			expected: []string{"*A"},
+		},
+		{
+			name:     "map_keys",
+			input:    `{"a": {"name": "x", "other": "y"}, "b": {"name": "x"}}`,
+			opts:     &jsonstruct.ParserOptions{MapKeys: []string{"a"}},
+			expected: []string{"map[string]string", "*B"},
+		},
+		{
+			name:     "map_keys_incompatible_values",
+			input:    `{"a": {"name": "x", "other": 1}}`,
+			opts:     &jsonstruct.ParserOptions{MapKeys: []string{"a"}},
+			expected: []string{"map[string]*json.RawMessage"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := strings.NewReader(test.input)
+			p, err := jsonstruct.NewParserWithOptions(r, slog.Default(), test.opts)
+			assert.Nil(t, err)
+
+			structs, err := p.Start()
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(structs))
+
+			types := []string{}
+			for _, field := range structs[0].Fields() {
+				types = append(types, field.Type())
+			}
+
+			assert.Equal(t, test.expected, types)
+		})
+	}
+}
+
+func TestParserNullable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"null", `{"a": null}`, []string{"*json.RawMessage"}},
+		{"null_then_string", `[{"a": null}, {"a": "x"}]`, []string{"*string"}},
+		{"int_then_null", `[{"a": 1}, {"a": null}, {"a": 2.5}]`, []string{"*float64"}},
+		{"always_null", `[{"a": null}, {"a": null}]`, []string{"*json.RawMessage"}},
+		{"null_struct", `[{"a": null}, {"a": {"b": 1}}]`, []string{"*A"}},
+		{"null_slice", `[{"a": [1]}, {"a": null}]`, []string{"[]int64"}},
+		{"null_and_incompatible", `[{"a": null}, {"a": 1}, {"a": "x"}]`, []string{"*json.RawMessage"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := strings.NewReader(test.input)
+			p := jsonstruct.NewParser(r, slog.Default())
+
+			structs, err := p.Start()
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(structs))
+
+			types := []string{}
+			for _, field := range structs[0].Fields() {
+				types = append(types, field.Type())
+			}
+
+			assert.Equal(t, test.expected, types)
+		})
+	}
+}
+
 func FuzzParser(f *testing.F) {
 	seeds := []string{
 		`{"a": 1}`,
@@ -142,3 +406,264 @@ func FuzzParser(f *testing.F) {
 		assert.Nil(t, err)
 	})
 }
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestParserLenient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		errors   bool
+	}{
+		{
+			name:     "line_comments",
+			input:    "{\n// the a\n\"a\": 1, // more a\n\"b\": 2\n}",
+			expected: []string{"A int64 // the a\nmore a", "B int64"},
+		},
+		{"block_comments", "{/* the\n * a */ \"a\": 1}", []string{"A int64 // the\na"}, false},
+		{"trailing_commas", `{"a": [1, 2,], "b": {"c": 1,},}`, []string{"A []int64", "B *B"}, false},
+		{"unquoted_keys", `{a: 1, $b_2: true}`, []string{"A int64", "B2 bool"}, false},
+		{"single_quotes", `{'a': 'it\'s "quoted"', 'b': '\x41'}`, []string{"A string", "B string"}, false},
+		{"numbers", `{a: 0x1F, b: .5, c: 5., d: +1, e: -Infinity, f: NaN}`, []string{
+			"A int64", "B float64", "C float64", "D int64", "E float64", "F float64",
+		}, false},
+		{"comment_after_object_key", "{\"a\": { // the a\n\"b\": 1}}", []string{"A *A // the a"}, false},
+		{"comment_after_array_value", "{\"a\": [1, // one\n2]}", []string{"A []int64"}, false},
+		{"merged_comments", "[{\"a\": 1}, {\n// the a\n\"a\": 2}]", []string{"A int64 // the a"}, false},
+		{"unterminated_comment", `{"a": 1 /* oops}`, nil, true},
+		{"unterminated_string", `{"a": 'oops}`, nil, true},
+		{"invalid_literal", `{"a": undefined}`, nil, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := strings.NewReader(test.input)
+			p, err := jsonstruct.NewParserWithOptions(r, slog.Default(), &jsonstruct.ParserOptions{Lenient: true})
+			assert.Nil(t, err)
+
+			structs, err := p.Start()
+			if test.errors {
+				assert.NotNil(t, err)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(structs))
+
+			fields := []string{}
+
+			for _, field := range structs[0].Fields() {
+				fieldStr := field.Name() + " " + field.Type()
+				if field.Doc() != "" {
+					fieldStr += " // " + field.Doc()
+				}
+
+				fields = append(fields, fieldStr)
+			}
+
+			assert.Equal(t, test.expected, fields)
+		})
+	}
+}
+
+func TestParserNDJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     *jsonstruct.ParserOptions
+		expected []string
+		errors   bool
+	}{
+		{
+			name:     "optional_fields",
+			input:    "{\"a\": 1, \"b\": \"x\"}\n{\"a\": 2.5}\n{\"a\": 3, \"c\": null}\n",
+			expected: []string{"A float64", "B string omitempty", "C *json.RawMessage omitempty"},
+		},
+		{
+			name:     "nullable",
+			input:    "{\"a\": null}\n{\"a\": 1}\n",
+			expected: []string{"A *int64"},
+		},
+		{
+			name:     "nested",
+			input:    "{\"a\": {\"b\": [1, 2]}}\n{\"a\": {\"b\": [null], \"c\": true}}\n{\"a\": {\"b\": []}}\n",
+			expected: []string{"A *A", "A.B []*int64", "A.C bool omitempty"},
+		},
+		{
+			name:     "struct_slices",
+			input:    "{\"a\": [{\"b\": 1}, {\"b\": 2}]}\n{\"a\": [{\"b\": 3, \"c\": 1}]}\n{\"a\": [{\"b\": 4, \"c\": 2}]}\n",
+			expected: []string{"A []*A", "A.B int64", "A.C int64 omitempty"},
+		},
+		{
+			name:     "arrays",
+			input:    "[{\"a\": 1}, {\"a\": 2}]\n[{\"b\": 1}]\n",
+			expected: []string{"A int64 omitempty", "B int64 omitempty"},
+		},
+		{
+			name:     "maps",
+			input:    "{\"1\": {\"a\": 1}}\n{\"2\": {\"b\": 2}}\n",
+			opts:     &jsonstruct.ParserOptions{DetectMaps: true},
+			expected: []string{"type Stdin map[string]*StdinValue"},
+		},
+		{
+			name:     "incompatible_values",
+			input:    "{\"a\": 1}\n{\"a\": \"x\"}\n",
+			expected: []string{"A *json.RawMessage"},
+		},
+		{"empty", "", nil, []string{}, false},
+		{"incompatible_samples", "{\"a\": 1}\n[1]\n", nil, nil, true},
+		{"invalid", "{\"a\": 1}\n{\"a\": }\n", nil, nil, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := test.opts
+			if opts == nil {
+				opts = &jsonstruct.ParserOptions{}
+			}
+
+			opts.NDJSON = true
+
+			r := strings.NewReader(test.input)
+			p, err := jsonstruct.NewParserWithOptions(r, slog.Default(), opts)
+			assert.Nil(t, err)
+
+			structs, err := p.Start()
+			if test.errors {
+				assert.NotNil(t, err)
+
+				return
+			}
+
+			assert.Nil(t, err)
+
+			fields := []string{}
+
+			for _, jStruct := range structs {
+				if jStruct.IsNamedType() {
+					formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{})
+					assert.Nil(t, err)
+
+					output, err := formatter.FormatStructs(jStruct.SetName("Stdin"))
+					assert.Nil(t, err)
+
+					fields = append(fields, strings.Split(strings.TrimSpace(output), "\n")[0])
+
+					continue
+				}
+
+				for _, field := range jStruct.Fields() {
+					fields = append(fields, ndjsonField("", field))
+
+					if field.HasStruct() {
+						for _, nested := range field.GetStruct().Fields() {
+							fields = append(fields, ndjsonField(field.Name()+".", nested))
+						}
+					}
+				}
+			}
+
+			assert.Equal(t, test.expected, fields)
+		})
+	}
+}
+
+func ndjsonField(prefix string, field *jsonstruct.Field) string {
+	result := prefix + field.Name() + " " + field.Type()
+	if strings.Contains(field.Tag(), "omitempty") {
+		result += " omitempty"
+	}
+
+	return result
+}
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestParserRootValues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     *jsonstruct.ParserOptions
+		expected string
+	}{
+		{"object", `{"a": 1}`, nil, "type Root struct {\n\tA int64 `json:\"a\"`\n}"},
+		{"map", `{"1": true}`, &jsonstruct.ParserOptions{DetectMaps: true}, "type Root map[string]bool"},
+		{"array_of_objects", `[{"a": 1}, {"a": 2}]`, nil, "type Root struct {\n\tA int64 `json:\"a\"`\n}"},
+		{"array_of_numbers", `[1, 2, 3]`, nil, "type Root []int64"},
+		{"array_of_nullable_strings", `["a", null]`, nil, "type Root []*string"},
+		{
+			name:     "array_of_arrays_of_objects",
+			input:    `[[{"a": 1}], [{"a": 2}]]`,
+			expected: "type Root [][]*RootValue\n\ntype RootValue struct {\n\tA int64 `json:\"a\"`\n}",
+		},
+		{"array_of_maps", `[{"1": 1}]`, &jsonstruct.ParserOptions{DetectMaps: true}, "type Root []map[string]int64"},
+		{"mixed_array", `[1, "a"]`, nil, "type Root []*json.RawMessage"},
+		{"empty_array", `[]`, nil, "type Root []*json.RawMessage"},
+		{"string", `"abc"`, nil, "type Root string"},
+		{"int", `1`, nil, "type Root int64"},
+		{"big_int", `123456789012345678901234567890`, nil, "type Root = big.Int"},
+		{"float", `1.5`, nil, "type Root float64"},
+		{"big_float", `1.5e1000`, nil, "type Root = big.Float"},
+		{"bool", `false`, nil, "type Root bool"},
+		{"null", `null`, nil, "type Root = json.RawMessage"},
+		{"time", `"2024-01-02T03:04:05Z"`, &jsonstruct.ParserOptions{InferTime: true}, "type Root = time.Time"},
+		{"string_number", `"123"`, &jsonstruct.ParserOptions{InferNumbers: true}, "type Root string"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := test.opts
+			if opts == nil {
+				opts = &jsonstruct.ParserOptions{}
+			}
+
+			parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(test.input), slog.Default(), opts)
+			assert.Nil(t, err)
+
+			structs, err := parser.Start()
+			assert.Nil(t, err)
+
+			if !assert.Equal(t, 1, len(structs)) {
+				return
+			}
+
+			formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{})
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs[0].SetName("Root"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(output))
+		})
+	}
+}
+
+func TestParserMultipleRootValues(t *testing.T) {
+	t.Parallel()
+
+	parser := jsonstruct.NewParser(strings.NewReader(`{"a": 1} null "x" [1] [{"b": 2}]`), slog.Default())
+
+	structs, err := parser.Start()
+	assert.Nil(t, err)
+
+	namedTypes := []bool{}
+	for _, jStruct := range structs {
+		namedTypes = append(namedTypes, jStruct.IsNamedType())
+	}
+
+	assert.Equal(t, []bool{false, true, true, true, false}, namedTypes)
+}
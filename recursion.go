@@ -0,0 +1,203 @@
+package jsonstruct
+
+// detectRecursion makes nested structs whose fields are a subset of one of the structs containing them refer to that
+// struct instead, if DetectRecursion is set: the replies of a comment become []*Comment rather than []*Replies, and so
+// on for every level of the samples. The fields of the ancestor that some levels lack become optional.
+func (f *FormatterOptions) detectRecursion(inputs []*JSONStruct) {
+	if !f.DetectRecursion {
+		return
+	}
+
+	var visit func(js *JSONStruct, ancestors []*JSONStruct)
+
+	visit = func(js *JSONStruct, ancestors []*JSONStruct) {
+		ancestors = append(ancestors[:len(ancestors):len(ancestors)], js)
+
+		for _, field := range js.typeFields() {
+			if !field.HasStruct() {
+				continue
+			}
+
+			nested := field.GetStruct()
+			if containsStruct(ancestors, nested) {
+				continue
+			}
+
+			// the closest ancestor wins, e.g. for a tree of folders nested in a project
+			if ancestor := matchingAncestor(nested, ancestors); ancestor != nil {
+				ancestor.named = true
+				field.SetValue(structValue(field, ancestor))
+
+				continue
+			}
+
+			visit(nested, ancestors)
+		}
+	}
+
+	for _, input := range inputs {
+		visit(input, nil)
+	}
+}
+
+// containsStruct returns true if js is one of structs.
+func containsStruct(structs []*JSONStruct, js *JSONStruct) bool {
+	for _, candidate := range structs {
+		if candidate == js {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchingAncestor returns the closest of ancestors that nested is a subset of, after changing it to accept the values
+// of nested, or nil if there isn't one. To avoid matching unrelated objects that happen to share a key, like an "id",
+// nested must have at least half of the fields of the ancestor, and at least two.
+func matchingAncestor(nested *JSONStruct, ancestors []*JSONStruct) *JSONStruct {
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		ancestor := ancestors[i]
+
+		// named types like maps don't have fields to compare
+		if ancestor.IsNamedType() || nested.IsNamedType() {
+			continue
+		}
+
+		if len(nested.Fields()) < max(2, (len(ancestor.Fields())+1)/2) {
+			continue
+		}
+
+		match := &recursionMatch{targets: map[*JSONStruct]*JSONStruct{}}
+		if match.isSubset(nested, ancestor, true) {
+			for _, update := range match.updates {
+				update()
+			}
+
+			return ancestor
+		}
+	}
+
+	return nil
+}
+
+// recursionMatch holds the state of the comparison of a nested struct with an ancestor.
+type recursionMatch struct {
+	// targets maps the structs being compared to the structs they are compared with, so that the levels of a recursive
+	// struct nested in the first one are compared with the ancestor rather than with each other
+	targets map[*JSONStruct]*JSONStruct
+	// updates are the changes required to make the ancestor accept the values of the nested struct
+	updates []func()
+}
+
+// isSubset returns true if the fields of nested are also fields of ancestor with the same types, comparing the structs
+// nested in both the same way. If strict isn't set, i.e. for structs that aren't levels of the recursive struct itself
+// (like the author of a comment), the fields of nested that ancestor lacks are added to it instead. Fields of ancestor
+// that nested lacks become optional.
+func (m *recursionMatch) isSubset(nested, ancestor *JSONStruct, strict bool) bool {
+	if target, ok := m.targets[nested]; nested == ancestor || ok && target == ancestor {
+		return true
+	}
+
+	m.targets[nested] = ancestor
+	ancestorFields := map[string]*Field{}
+
+	for _, field := range ancestor.Fields() {
+		ancestorFields[field.OriginalName()] = field
+	}
+
+	for _, field := range nested.Fields() {
+		ancestorField, ok := ancestorFields[field.OriginalName()]
+
+		switch {
+		case !ok && strict:
+			return false
+		case !ok:
+			field := field
+			m.updates = append(m.updates, func() { addMissingField(ancestor, field) })
+
+			continue
+		case !m.isSubsetField(field, ancestorField):
+			return false
+		}
+
+		delete(ancestorFields, field.OriginalName())
+
+		if field.optional {
+			m.updates = append(m.updates, func() { ancestorField.SetOptional() })
+		}
+	}
+
+	// fields nested doesn't have
+	for _, ancestorField := range ancestorFields {
+		ancestorField := ancestorField
+		m.updates = append(m.updates, func() { ancestorField.SetOptional() })
+	}
+
+	return true
+}
+
+// isSubsetField returns true if ancestor can hold the values of field, which has the same key in a nested struct.
+func (m *recursionMatch) isSubsetField(field, ancestor *Field) bool {
+	if field.Nullable() || field.IsNull() {
+		m.updates = append(m.updates, func() { ancestor.SetNullable() })
+	}
+
+	switch {
+	case field.IsNull():
+		return true
+	case field.IsSlice() && ancestor.IsSlice():
+		// empty slices, e.g. the children of a leaf, have null elements
+		return m.isSubsetField(field.SliceElementField(), ancestor.SliceElementField())
+	case field.IsMap() && ancestor.IsMap():
+		return m.isSubsetField(field.MapValueField(), ancestor.MapValueField())
+	case !field.IsStruct() || !ancestor.IsStruct():
+		return field.baseType() == ancestor.baseType()
+	}
+
+	nested, ancestorStruct := field.GetStruct(), ancestor.GetStruct()
+
+	// a level of the recursive struct, e.g. the replies of the replies of a comment
+	if target, ok := m.targets[ancestorStruct]; ok {
+		return m.isSubset(nested, target, true)
+	}
+
+	return m.isSubset(nested, ancestorStruct, false)
+}
+
+// addMissingField adds field to js as an optional field, unless another level of a recursive struct already added a
+// field with the same key.
+func addMissingField(js *JSONStruct, field *Field) {
+	for _, existing := range js.Fields() {
+		if existing.OriginalName() == field.OriginalName() {
+			return
+		}
+	}
+
+	js.AddFields(field.SetOptional())
+}
+
+// structValue returns a value shaped like the value of field, holding js instead of the struct within it.
+func structValue(field *Field, js *JSONStruct) any {
+	switch {
+	case field.IsSlice():
+		element := field.SliceElementField()
+		values := []any{structValue(element, js)}
+
+		if element.Nullable() {
+			values = append(values, nil)
+		}
+
+		return values
+	case field.IsMap():
+		value := field.MapValueField()
+		jMap := &jsonMap{fields: Fields{NewField().SetName(value.OriginalName()).SetValue(structValue(value, js))}}
+
+		if value.Nullable() {
+			jMap.fields = append(jMap.fields, NewField().SetName(value.OriginalName()))
+		}
+
+		return jMap
+	}
+
+	return js
+}
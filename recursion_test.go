@@ -0,0 +1,96 @@
+package jsonstruct_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestFormatterRecursion(t *testing.T) {
+	t.Parallel()
+
+	tree := `{"name": "root", "children": [{"name": "a", "children": [{"name": "a1", "children": []}, {"name": "a2"}]}]}`
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     *jsonstruct.FormatterOptions
+		expected string
+	}{
+		{
+			name:  "disabled",
+			input: tree,
+			opts:  &jsonstruct.FormatterOptions{},
+			expected: "type Node struct {\n\tName     string          `json:\"name\"`\n" +
+				"\tChildren []*NodeChildren `json:\"children\"`\n}\n\n" +
+				"type NodeChildren struct {\n\tName     string                  `json:\"name\"`\n" +
+				"\tChildren []*NodeChildrenChildren `json:\"children\"`\n}\n\n" +
+				"type NodeChildrenChildren struct {\n\tName     string             `json:\"name\"`\n" +
+				"\tChildren []*json.RawMessage `json:\"children,omitempty\"`\n}",
+		},
+		{
+			name:  "tree",
+			input: tree,
+			opts:  &jsonstruct.FormatterOptions{DetectRecursion: true},
+			expected: "type Node struct {\n\tName     string  `json:\"name\"`\n" +
+				"\tChildren []*Node `json:\"children,omitempty\"`\n}",
+		},
+		{
+			name: "comments",
+			input: `{"id": 1, "author": {"name": "a"}, "replies": [{"id": 2, "author": {"name": "b"}, ` +
+				`"replies": [{"id": 3, "author": {"name": "c", "karma": 3}}]}], "meta": {"id": 4}}`,
+			opts: &jsonstruct.FormatterOptions{DetectRecursion: true},
+			expected: "type Node struct {\n\tID      int64   `json:\"id\"`\n\tAuthor  *Author `json:\"author\"`\n" +
+				"\tReplies []*Node `json:\"replies,omitempty\"`\n\tMeta    *Meta   `json:\"meta,omitempty\"`\n}\n\n" +
+				"type Author struct {\n\tName  string `json:\"name\"`\n\tKarma int64  `json:\"karma,omitempty\"`\n}\n\n" +
+				"type Meta struct {\n\tID int64 `json:\"id\"`\n}",
+		},
+		{
+			name:  "single_object",
+			input: `{"id": 1, "name": "a", "parent": {"id": 2, "name": "b", "parent": null}}`,
+			opts:  &jsonstruct.FormatterOptions{DetectRecursion: true},
+			expected: "type Node struct {\n\tID     int64  `json:\"id\"`\n\tName   string `json:\"name\"`\n" +
+				"\tParent *Node  `json:\"parent\"`\n}",
+		},
+		{
+			name:  "different_types",
+			input: `{"name": "a", "size": 1, "children": [{"name": "b", "size": "large"}]}`,
+			opts:  &jsonstruct.FormatterOptions{DetectRecursion: true},
+			expected: "type Node struct {\n\tName     string      `json:\"name\"`\n\tSize     int64       `json:\"size\"`\n" +
+				"\tChildren []*Children `json:\"children\"`\n}\n\n" +
+				"type Children struct {\n\tName string `json:\"name\"`\n\tSize string `json:\"size\"`\n}",
+		},
+		{
+			name:  "extra_fields",
+			input: `{"title": "p", "folder": {"title": "f", "files": [{"title": "a", "size": 1}]}}`,
+			opts:  &jsonstruct.FormatterOptions{DetectRecursion: true},
+			expected: "type Node struct {\n\tTitle  string  `json:\"title\"`\n\tFolder *Folder `json:\"folder\"`\n}\n\n" +
+				"type Folder struct {\n\tTitle string   `json:\"title\"`\n\tFiles []*Files `json:\"files\"`\n}\n\n" +
+				"type Files struct {\n\tTitle string `json:\"title\"`\n\tSize  int64  `json:\"size\"`\n}",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := jsonstruct.NewParser(strings.NewReader(test.input), slog.Default())
+
+			structs, err := parser.Start()
+			assert.Nil(t, err)
+
+			formatter, err := jsonstruct.NewFormatter(test.opts)
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs[0].SetName("Node"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(output))
+		})
+	}
+}
@@ -0,0 +1,873 @@
+package jsonstruct
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidSchema = errors.New("invalid JSON Schema")
+
+// anyValue is the value of fields that can hold any JSON value, e.g. ones described by an empty schema. They are typed
+// as *json.RawMessage.
+type anyValue struct{}
+
+// SchemaParser builds JSONStructs from a JSON Schema (draft 2020-12) describing the input, rather than from samples of
+// it. Properties that aren't required are optional, $defs referenced with $ref become shared types named after their
+// key, enum / const / examples / default values are used as example values, and the variants of oneOf / anyOf are
+// merged the same way the elements of an array are. String enums are declared as enum types if the Formatter detects
+// enums, and the title of the root schema names it unless the TypeNamer has a pattern.
+type SchemaParser struct {
+	log   *slog.Logger
+	input io.Reader
+	root  any
+	// refs contains the samples built for each $ref, so that definitions used in several places share their structs
+	refs map[string][]any
+	// resolving contains the $refs being built, to catch definitions that can only be represented by referring to
+	// themselves without a struct
+	resolving map[string]bool
+}
+
+// NewSchemaParser returns a SchemaParser reading a JSON Schema document from input.
+func NewSchemaParser(input io.Reader, logger *slog.Logger) *SchemaParser {
+	return &SchemaParser{
+		log:       logger,
+		input:     input,
+		refs:      map[string][]any{},
+		resolving: map[string]bool{},
+	}
+}
+
+// newDocumentSchemaParser returns a SchemaParser for schemas within root, an already decoded document, e.g. the
+// schemas of an OpenAPI document.
+func newDocumentSchemaParser(root any, logger *slog.Logger) *SchemaParser {
+	parser := NewSchemaParser(nil, logger)
+	parser.root = root
+
+	return parser
+}
+
+// Start decodes the schema and returns a JSONStruct for its root: a struct if it describes an object, or a named type
+// otherwise. Definitions that aren't used by the root schema are ignored.
+func (s *SchemaParser) Start() (JSONStructs, error) {
+	decoder := json.NewDecoder(s.input)
+	decoder.UseNumber()
+
+	root, err := decodeSchemaValue(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode schema: %w", err)
+	}
+
+	s.root = root
+
+	js, err := s.rootStruct("#", root, "#")
+	if err != nil {
+		return nil, err
+	}
+
+	if obj, ok := root.(*schemaObject); ok {
+		js.title, _ = obj.str("title")
+	}
+
+	return JSONStructs{js}, nil
+}
+
+// rootStruct returns the top-level JSONStruct for schema, found at path in the document: a struct if it describes an
+// object, or a named type otherwise.
+func (s *SchemaParser) rootStruct(path string, schema any, ref string) (*JSONStruct, error) {
+	samples, err := s.samples(path, schema, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	field := NewField().setMergedValue(samples)
+
+	if js, ok := field.rawValue.(*JSONStruct); ok && !field.Nullable() && !field.isJSONRaw {
+		return js, nil
+	}
+
+	return newNamedType(field), nil
+}
+
+// samples returns values that, merged together, have the type described by schema, which is found at path. If schema
+// is the target of ref, structs built for it are registered before their properties, so that they can refer to
+// themselves.
+func (s *SchemaParser) samples(path string, schema any, ref string) ([]any, error) {
+	obj, ok := schema.(*schemaObject)
+	if !ok {
+		// true accepts anything, false accepts nothing
+		if accepts, ok := schema.(bool); ok {
+			if accepts {
+				return []any{anyValue{}}, nil
+			}
+
+			return []any{}, nil
+		}
+
+		return nil, fmt.Errorf("%s: expecting an object or a boolean, got %T: %w", path, schema, ErrInvalidSchema)
+	}
+
+	if target, ok := obj.str("$ref"); ok {
+		return s.refSamples(path, target)
+	}
+
+	results, err := s.ownSamples(path, obj, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(obj.list("allOf")) > 0 {
+		if results, err = s.allOfSamples(path+"/allOf", obj, ref, results); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if variants := obj.list(keyword); len(variants) > 0 {
+			if results, err = s.variantSamples(path+"/"+keyword, results, variants); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// refSamples returns the samples of the schema referred to by ref. Only references within the document are supported;
+// anything else can hold any value.
+func (s *SchemaParser) refSamples(path, ref string) ([]any, error) {
+	if samples, ok := s.refs[ref]; ok {
+		return samples, nil
+	}
+
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok || (pointer != "" && !strings.HasPrefix(pointer, "/")) {
+		s.log.Warn("unsupported $ref, accepting any value", "path", path, "ref", ref)
+
+		return []any{anyValue{}}, nil
+	}
+
+	// e.g. an array of itself, which can't be represented without declaring a named slice type
+	if s.resolving[ref] {
+		s.log.Warn("recursive $ref outside of an object, accepting any value", "path", path, "ref", ref)
+
+		return []any{anyValue{}}, nil
+	}
+
+	target, err := s.resolve(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to resolve $ref %q: %w", path, ref, err)
+	}
+
+	s.resolving[ref] = true
+	defer delete(s.resolving, ref)
+
+	samples, err := s.samples(ref, target, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	s.refs[ref] = samples
+
+	return samples, nil
+}
+
+// resolve returns the value found at the JSON pointer in the schema document, e.g. "/$defs/user".
+func (s *SchemaParser) resolve(pointer string) (any, error) {
+	current := s.root
+	if pointer == "" {
+		return current, nil
+	}
+
+	var ok bool
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token, err := url.PathUnescape(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON pointer %q: %w", pointer, ErrInvalidSchema)
+		}
+
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch val := current.(type) {
+		case *schemaObject:
+			if current, ok = val.values[token]; !ok {
+				return nil, fmt.Errorf("%q not found: %w", token, ErrInvalidSchema)
+			}
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(val) {
+				return nil, fmt.Errorf("invalid index %q: %w", token, ErrInvalidSchema)
+			}
+
+			current = val[index]
+		default:
+			return nil, fmt.Errorf("can't look up %q in %T: %w", token, current, ErrInvalidSchema)
+		}
+	}
+
+	return current, nil
+}
+
+// ownSamples returns the samples described by the keywords of schema, ignoring allOf, oneOf, and anyOf.
+func (s *SchemaParser) ownSamples(path string, schema *schemaObject, ref string) ([]any, error) {
+	types, err := schemaTypes(path, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	// without a type, the type of the allowed values is used, if any
+	if len(types) == 0 {
+		values := schema.list("enum")
+		if value, ok := schema.values["const"]; ok {
+			values = []any{value}
+		}
+
+		if len(values) == 0 {
+			return []any{anyValue{}}, nil
+		}
+
+		results := []any{}
+
+		for _, value := range values {
+			results = append(results, schemaValue(schema, value))
+		}
+
+		return results, nil
+	}
+
+	results := []any{}
+
+	for _, typ := range types {
+		var sample any
+
+		switch typ {
+		case "null":
+			sample = nil
+		case "boolean":
+			sample, _ = schema.example(isType[bool]).(bool)
+		case "integer", "number":
+			sample = numberSample(schema, typ)
+		case "string":
+			str, _ := schema.example(isType[string]).(string)
+			sample = stringSample(schema, str)
+		case "array":
+			if sample, err = s.arraySample(path, schema); err != nil {
+				return nil, err
+			}
+		case "object":
+			if sample, err = s.objectSample(path, schema, ref); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown type %q: %w", path, typ, ErrInvalidSchema)
+		}
+
+		results = append(results, sample)
+	}
+
+	// OpenAPI 3.0 marks nullable values with "nullable" rather than a "null" type
+	if nullable, _ := schema.values["nullable"].(bool); nullable {
+		results = append(results, nil)
+	}
+
+	return results, nil
+}
+
+// schemaTypes returns the types listed by schema, or the type implied by its keywords if it doesn't have any.
+func schemaTypes(path string, schema *schemaObject) ([]string, error) {
+	switch val := schema.values["type"].(type) {
+	case string:
+		return []string{val}, nil
+	case []any:
+		types := []string{}
+
+		for _, typ := range val {
+			typStr, ok := typ.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: invalid type %v: %w", path, typ, ErrInvalidSchema)
+			}
+
+			types = append(types, typStr)
+		}
+
+		return types, nil
+	case nil:
+		for _, keyword := range []string{"properties", "additionalProperties", "patternProperties", "required"} {
+			if _, ok := schema.values[keyword]; ok {
+				return []string{"object"}, nil
+			}
+		}
+
+		for _, keyword := range []string{"items", "prefixItems"} {
+			if _, ok := schema.values[keyword]; ok {
+				return []string{"array"}, nil
+			}
+		}
+
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("%s: invalid type %v: %w", path, schema.values["type"], ErrInvalidSchema)
+}
+
+// numberSample returns the sample for an "integer" or "number" schema, using its example values if there are any.
+func numberSample(schema *schemaObject, typ string) any {
+	number, _ := schema.example(isType[json.Number]).(json.Number)
+
+	sample := numberValue(number)
+	kind := getNumberKind(sample)
+
+	if typ == "integer" {
+		if kind != kindInt64 && kind != kindBigInt {
+			return int64(0)
+		}
+
+		return sample
+	}
+
+	return convertNumber(sample, unifyNumberKinds(kind, kindFloat64))
+}
+
+// numberValue converts number to the type the Parser would use for it, or 0 if it's empty or invalid.
+func numberValue(number json.Number) any {
+	numberStr := string(number)
+
+	if !strings.ContainsAny(numberStr, ".eE") {
+		if val, err := strconv.ParseInt(numberStr, 10, 64); err == nil {
+			return val
+		}
+
+		if val, ok := (&big.Int{}).SetString(numberStr, 10); ok {
+			return val
+		}
+	}
+
+	val, err := strconv.ParseFloat(numberStr, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if bigVal, _, err := (&big.Float{}).Parse(numberStr, 10); err == nil {
+			return bigVal
+		}
+	}
+
+	if err != nil {
+		return int64(0)
+	}
+
+	return val
+}
+
+// schemaValue converts a value from schema, e.g. one of its enum values, to the sample the Parser would use for it.
+func schemaValue(schema *schemaObject, value any) any {
+	switch val := value.(type) {
+	case json.Number:
+		return numberValue(val)
+	case string:
+		return stringSample(schema, val)
+	case bool, nil:
+		return val
+	}
+
+	// objects and arrays
+	return anyValue{}
+}
+
+// stringEnum returns the values of the enum of schema, following its $ref, or nil if it doesn't have an enum of
+// strings.
+func (s *SchemaParser) stringEnum(schema any) *stringValues {
+	obj, ok := schema.(*schemaObject)
+	if !ok {
+		return nil
+	}
+
+	if ref, hasRef := obj.str("$ref"); hasRef {
+		pointer, isLocal := strings.CutPrefix(ref, "#")
+		if !isLocal {
+			return nil
+		}
+
+		target, err := s.resolve(pointer)
+		if obj, ok = target.(*schemaObject); err != nil || !ok {
+			return nil
+		}
+	}
+
+	values := obj.list("enum")
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := &stringValues{samples: len(values), listed: true}
+
+	for _, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			return nil
+		}
+
+		result.values = append(result.values, str)
+	}
+
+	return result
+}
+
+// stringSample returns str as a formattedString if the schema has a "format", which determines its Go type and
+// validate rule, otherwise it returns str unchanged.
+func stringSample(schema *schemaObject, str string) any {
+	if format, _ := schema.str("format"); format != "" {
+		return formattedString{value: str, format: StringFormat(format)}
+	}
+
+	return str
+}
+
+// arraySample returns a slice holding the samples of the items of an array schema.
+func (s *SchemaParser) arraySample(path string, schema *schemaObject) ([]any, error) {
+	elements := []any{}
+
+	for i, item := range schema.list("prefixItems") {
+		samples, err := s.samples(fmt.Sprintf("%s/prefixItems/%d", path, i), item, "")
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, samples...)
+	}
+
+	if items, ok := schema.values["items"]; ok {
+		samples, err := s.samples(path+"/items", items, "")
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, samples...)
+	}
+
+	return elements, nil
+}
+
+// objectSample returns a struct with a field for each of the properties of an object schema, or a map if it doesn't
+// have any.
+func (s *SchemaParser) objectSample(path string, schema *schemaObject, ref string) (any, error) {
+	properties, _ := schema.values["properties"].(*schemaObject)
+	if properties == nil {
+		return s.mapSample(path, schema)
+	}
+
+	result := New()
+	s.nameStruct(result, schema, ref)
+
+	required := schema.required()
+
+	for _, key := range properties.keys {
+		samples, err := s.samples(path+"/properties/"+pointerToken(key), properties.values[key], "")
+		if err != nil {
+			return nil, err
+		}
+
+		field := NewField().SetName(key).setMergedValue(samples)
+
+		// the values of the enum are complete, so the Formatter can declare them even if they are few
+		if _, isString := field.rawValue.(string); isString {
+			field.strings = s.stringEnum(properties.values[key])
+		}
+
+		if property, ok := properties.values[key].(*schemaObject); ok {
+			doc, _ := property.str("description")
+			field.SetDoc(doc)
+		}
+
+		if !slices.Contains(required, key) {
+			field.SetOptional()
+		}
+
+		result.AddFields(field)
+	}
+
+	return result, nil
+}
+
+// nameStruct names js after the title of its schema, or after the definition ref points to, in which case js is also
+// registered as the struct for ref so that it can refer to itself. The root schema is named later.
+func (s *SchemaParser) nameStruct(js *JSONStruct, schema *schemaObject, ref string) {
+	title, hasTitle := schema.str("title")
+	if ref == "" && !hasTitle {
+		return
+	}
+
+	js.named = true
+
+	switch {
+	case hasTitle:
+		js.name = GetGoName(title)
+	case ref != "#":
+		js.name = GetGoName(ref[strings.LastIndex(ref, "/")+1:])
+	default:
+		js.name = ""
+	}
+
+	if ref != "" {
+		s.refs[ref] = []any{js}
+	}
+}
+
+// mapSample returns a map whose values have the samples of additionalProperties and patternProperties. If neither of
+// them are present, the map can hold any value, unless additionalProperties is false, in which case it's an empty
+// struct.
+func (s *SchemaParser) mapSample(path string, schema *schemaObject) (any, error) {
+	valueSchemas := []any{}
+	valuePaths := []string{}
+
+	if patterns, ok := schema.values["patternProperties"].(*schemaObject); ok {
+		for _, pattern := range patterns.keys {
+			valueSchemas = append(valueSchemas, patterns.values[pattern])
+			valuePaths = append(valuePaths, path+"/patternProperties/"+pattern)
+		}
+	}
+
+	switch additional := schema.values["additionalProperties"].(type) {
+	case *schemaObject:
+		valueSchemas = append(valueSchemas, additional)
+		valuePaths = append(valuePaths, path+"/additionalProperties")
+	case bool:
+		if !additional && len(valueSchemas) == 0 {
+			return New(), nil
+		}
+	}
+
+	result := &jsonMap{}
+
+	for i, valueSchema := range valueSchemas {
+		samples, err := s.samples(valuePaths[i], valueSchema, "")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sample := range samples {
+			result.fields = append(result.fields, NewField().SetValue(sample))
+		}
+	}
+
+	return result, nil
+}
+
+// allOfSamples combines base, the samples of schema's own keywords, with those of its allOf branches. Branches that
+// don't have a type, e.g. ones only listing required properties, are ignored, and the properties of objects are
+// combined into a single struct. Otherwise, the first branch with a type wins.
+func (s *SchemaParser) allOfSamples(path string, schema *schemaObject, ref string, base []any) ([]any, error) {
+	parts := [][]any{}
+	required := schema.required()
+
+	if !isAnySamples(base) {
+		parts = append(parts, base)
+	}
+
+	for i, branch := range schema.list("allOf") {
+		if branchObj, ok := branch.(*schemaObject); ok {
+			required = append(required, branchObj.required()...)
+		}
+
+		samples, err := s.samples(fmt.Sprintf("%s/%d", path, i), branch, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if !isAnySamples(samples) {
+			parts = append(parts, samples)
+		}
+	}
+
+	if len(parts) == 0 {
+		return base, nil
+	}
+
+	structs := JSONStructs{}
+
+	for _, part := range parts {
+		if js := singleStruct(part); js != nil {
+			structs = append(structs, js)
+		}
+	}
+
+	if len(structs) != len(parts) {
+		return parts[0], nil
+	}
+
+	// the schema's own struct may be referred to already, so it's extended rather than replaced
+	result := New()
+	if isAnySamples(base) {
+		s.nameStruct(result, schema, ref)
+	} else {
+		result, structs = structs[0], structs[1:]
+	}
+
+	// properties can be required by a different branch than the one describing them
+	for _, field := range combineStructs(result, structs...).Fields() {
+		if slices.Contains(required, field.OriginalName()) {
+			field.optional = false
+		}
+	}
+
+	return []any{result}, nil
+}
+
+// variantSamples returns the samples of the variants of oneOf / anyOf, which are merged like the elements of an
+// array. Variants without a type are ignored, and if the schema itself describes an object, its properties are added
+// to each of the variants.
+func (s *SchemaParser) variantSamples(path string, base []any, variants []any) ([]any, error) {
+	results := []any{}
+
+	for i, variant := range variants {
+		samples, err := s.samples(fmt.Sprintf("%s/%d", path, i), variant, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if !isAnySamples(samples) {
+			results = append(results, samples...)
+		}
+	}
+
+	baseStruct := singleStruct(base)
+
+	switch {
+	case len(results) == 0:
+		return base, nil
+	case baseStruct != nil && isStructSlice(results):
+		for i, result := range results {
+			variant, _ := result.(*JSONStruct)
+			results[i] = combineStructs(New(), baseStruct, variant)
+		}
+	case !isAnySamples(base) && !isEmptyMap(base):
+		return base, nil
+	}
+
+	return results, nil
+}
+
+// combineStructs adds the fields of structs to result, unless it already has a field with the same name, in which case
+// that field is only optional if it is optional everywhere. Returns result.
+func combineStructs(result *JSONStruct, structs ...*JSONStruct) *JSONStruct {
+	for _, js := range structs {
+		for _, field := range js.Fields() {
+			index := slices.IndexFunc(result.fields, func(existing *Field) bool {
+				return existing.OriginalName() == field.OriginalName()
+			})
+
+			if index == -1 {
+				copied := *field
+				result.AddFields(&copied)
+			} else if !field.optional {
+				result.fields[index].optional = false
+			}
+		}
+	}
+
+	return result
+}
+
+// isAnySamples returns true if samples don't restrict the type of the value.
+func isAnySamples(samples []any) bool {
+	return len(samples) == 1 && samples[0] == anyValue{}
+}
+
+// isEmptyMap returns true if samples are a single map that can hold any value, e.g. from a schema that is only
+// {"type": "object"}.
+func isEmptyMap(samples []any) bool {
+	if len(samples) != 1 {
+		return false
+	}
+
+	jMap, ok := samples[0].(*jsonMap)
+
+	return ok && len(jMap.fields) == 0
+}
+
+// pointerToken escapes key for use in a JSON pointer.
+func pointerToken(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// isType returns true if value is a T.
+func isType[T any](value any) bool {
+	_, ok := value.(T)
+
+	return ok
+}
+
+// singleStruct returns the struct in samples if it's the only sample, or nil otherwise.
+func singleStruct(samples []any) *JSONStruct {
+	if len(samples) != 1 {
+		return nil
+	}
+
+	js, _ := samples[0].(*JSONStruct)
+
+	return js
+}
+
+// schemaObject is a JSON object from a schema document, which keeps its keys in order so that fields are declared in
+// the order of the properties describing them.
+type schemaObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func newSchemaObject() *schemaObject {
+	return &schemaObject{values: map[string]any{}}
+}
+
+// set sets the value of key, which is added after the existing keys if it's new.
+func (o *schemaObject) set(key string, value any) *schemaObject {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+
+	o.values[key] = value
+
+	return o
+}
+
+// MarshalJSON encodes the object with its keys in order.
+func (o *schemaObject) MarshalJSON() ([]byte, error) {
+	var result bytes.Buffer
+
+	encoder := json.NewEncoder(&result)
+	encoder.SetEscapeHTML(false)
+
+	result.WriteByte('{')
+
+	for i, key := range o.keys {
+		if i > 0 {
+			result.WriteByte(',')
+		}
+
+		if err := encoder.Encode(key); err != nil {
+			return nil, fmt.Errorf("failed to encode key %q: %w", key, err)
+		}
+
+		result.WriteByte(':')
+
+		if err := encoder.Encode(o.values[key]); err != nil {
+			return nil, fmt.Errorf("failed to encode value of %q: %w", key, err)
+		}
+	}
+
+	result.WriteByte('}')
+
+	return result.Bytes(), nil
+}
+
+// str returns the string value of key, if it has one.
+func (o *schemaObject) str(key string) (string, bool) {
+	val, ok := o.values[key].(string)
+
+	return val, ok
+}
+
+// list returns the array value of key, if it has one.
+func (o *schemaObject) list(key string) []any {
+	val, _ := o.values[key].([]any)
+
+	return val
+}
+
+// required returns the names listed in the schema's "required" keyword.
+func (o *schemaObject) required() []string {
+	results := []string{}
+
+	for _, name := range o.list("required") {
+		if nameStr, ok := name.(string); ok {
+			results = append(results, nameStr)
+		}
+	}
+
+	return results
+}
+
+// example returns the first of the schema's examples, const, default, and enum values that is accepted by match, or nil
+// if there isn't one. OpenAPI 3.0's "example" is accepted as well.
+func (o *schemaObject) example(match func(any) bool) any {
+	candidates := o.list("examples")
+
+	for _, key := range []string{"example", "const", "default"} {
+		if value, ok := o.values[key]; ok {
+			candidates = append(candidates, value)
+		}
+	}
+
+	for _, candidate := range append(candidates, o.list("enum")...) {
+		if match(candidate) {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+// decodeSchemaValue decodes the next JSON value from decoder, which must use json.Number, with objects decoded as
+// *schemaObject.
+func decodeSchemaValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next token: %w", err)
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	var result any
+
+	switch delim {
+	case '{':
+		obj := newSchemaObject()
+
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get key: %w", err)
+			}
+
+			key, _ := keyToken.(string)
+
+			value, err := decodeSchemaValue(decoder)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %q: %w", key, err)
+			}
+
+			obj.set(key, value)
+		}
+
+		result = obj
+	case '[':
+		list := []any{}
+
+		for decoder.More() {
+			value, err := decodeSchemaValue(decoder)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode element %d: %w", len(list), err)
+			}
+
+			list = append(list, value)
+		}
+
+		result = list
+	}
+
+	// the closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("failed to get end of value: %w", err)
+	}
+
+	return result, nil
+}
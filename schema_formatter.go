@@ -0,0 +1,328 @@
+package jsonstruct
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// SchemaURI identifies the JSON Schema dialect of the documents rendered by SchemaFormatter.
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaFormatter renders JSONStructs as a JSON Schema (draft 2020-12) document. With a single input, the document
+// describes it; with several, each of them is declared in $defs and the document accepts any of them. Nested structs
+// are declared in $defs as well, unless InlineStructs is set, and they are named the same way the Formatter names them.
+// Fields that aren't optional are required, and fields that are nullable also accept null.
+//
+// ValueComments adds the example values of fields as "examples", and doc comments become descriptions. PackageName,
+// GeneratedHeader, NullType, and Overrides don't apply to JSON Schemas and are ignored.
+type SchemaFormatter struct {
+	*FormatterOptions
+}
+
+// NewSchemaFormatter returns an initialized SchemaFormatter.
+func NewSchemaFormatter(opts *FormatterOptions) (*SchemaFormatter, error) {
+	if err := opts.OK(); err != nil {
+		return nil, fmt.Errorf("invalid formatter options: %w", err)
+	}
+
+	return &SchemaFormatter{FormatterOptions: opts}, nil
+}
+
+// schemaDocument holds the state of a JSON Schema being rendered.
+type schemaDocument struct {
+	*SchemaFormatter
+	// root is the struct described by the document itself, if there is only one input
+	root *JSONStruct
+	defs *schemaObject
+}
+
+// FormatStructs renders inputs, as well as any structs nested within them, as a JSON Schema document.
+func (s *SchemaFormatter) FormatStructs(inputs ...*JSONStruct) (string, error) {
+	if err := s.checkNames(inputs); err != nil {
+		return "", err
+	}
+
+	s.nameStructs(inputs)
+	s.detectEnums(inputs)
+
+	doc := &schemaDocument{SchemaFormatter: s, defs: newSchemaObject()}
+	result := newSchemaObject().set("$schema", SchemaURI)
+
+	switch {
+	case len(inputs) == 1 && !inputs[0].fromArray:
+		doc.root = inputs[0]
+
+		rootSchema, err := doc.structSchema(doc.root)
+		if err != nil {
+			return "", fmt.Errorf("failed to render schema for %s: %w", doc.root.Name(), err)
+		}
+
+		result.set("title", doc.root.Name())
+
+		for _, key := range rootSchema.keys {
+			result.set(key, rootSchema.values[key])
+		}
+	case len(inputs) == 1:
+		arraySchema, err := doc.inputRef(inputs[0])
+		if err != nil {
+			return "", fmt.Errorf("failed to render schema for %s: %w", inputs[0].Name(), err)
+		}
+
+		result.set("title", inputs[0].Name())
+
+		for _, key := range arraySchema.keys {
+			result.set(key, arraySchema.values[key])
+		}
+	default:
+		refs := []any{}
+
+		for _, input := range inputs {
+			ref, err := doc.inputRef(input)
+			if err != nil {
+				return "", fmt.Errorf("failed to render schema for %s: %w", input.Name(), err)
+			}
+
+			refs = append(refs, ref)
+		}
+
+		result.set("anyOf", refs)
+	}
+
+	if len(doc.defs.keys) > 0 {
+		result.set("$defs", doc.defs)
+	}
+
+	var output bytes.Buffer
+
+	encoder := json.NewEncoder(&output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(result); err != nil {
+		return "", fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	return output.String(), nil
+}
+
+// structSchema returns the schema for js: an object with its fields as properties, or the schema of its value if it's
+// a named type.
+func (d *schemaDocument) structSchema(js *JSONStruct) (*schemaObject, error) {
+	if js.IsNamedType() {
+		return d.valueSchema(js.value)
+	}
+
+	fields := append(Fields{}, js.Fields()...)
+	if d.SortFields {
+		fields.SortAlphabetically()
+	}
+
+	properties := newSchemaObject()
+	required := []string{}
+
+	for _, field := range fields {
+		fieldSchema, err := d.fieldSchema(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render field %s: %w", field.Name(), err)
+		}
+
+		properties.set(field.OriginalName(), fieldSchema)
+
+		if !field.optional {
+			required = append(required, field.OriginalName())
+		}
+	}
+
+	result := newSchemaObject().set("type", "object").set("properties", properties)
+	if len(required) > 0 {
+		result.set("required", required)
+	}
+
+	return result, nil
+}
+
+// inputRef returns a reference to the schema of the top-level struct js, or the schema of an array of them if it was
+// merged from the objects of an array.
+func (d *schemaDocument) inputRef(js *JSONStruct) (*schemaObject, error) {
+	ref, err := d.structRef(js)
+	if err != nil {
+		return nil, err
+	}
+
+	if js.fromArray {
+		return newSchemaObject().set("type", "array").set("items", ref), nil
+	}
+
+	return ref, nil
+}
+
+// structRef returns a reference to the schema of js, which is added to $defs the first time it's used, or the schema
+// itself if InlineStructs is set.
+func (d *schemaDocument) structRef(js *JSONStruct) (*schemaObject, error) {
+	if d.InlineStructs {
+		if js.isRecursive() {
+			return nil, fmt.Errorf("failed to inline %s: %w", js.Name(), ErrRecursiveInline)
+		}
+
+		return d.structSchema(js)
+	}
+
+	if js == d.root {
+		return newSchemaObject().set("$ref", "#"), nil
+	}
+
+	ref := newSchemaObject().set("$ref", "#/$defs/"+js.Name())
+
+	if _, ok := d.defs.values[js.Name()]; ok {
+		return ref, nil
+	}
+
+	// declared before rendering it, so that it can refer to itself
+	d.defs.set(js.Name(), nil)
+
+	defSchema, err := d.structSchema(js)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render definition %s: %w", js.Name(), err)
+	}
+
+	d.defs.set(js.Name(), defSchema)
+
+	return ref, nil
+}
+
+// fieldSchema returns the schema for the value of field, along with its description and examples.
+func (d *schemaDocument) fieldSchema(field *Field) (*schemaObject, error) {
+	result, err := d.valueSchema(field)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc := field.Doc(); doc != "" {
+		result.set("description", doc)
+	}
+
+	if example := exampleValue(field.rawValue); d.ValueComments && example != nil && !field.isJSONRaw {
+		result.set("examples", []any{example})
+	}
+
+	return result, nil
+}
+
+// valueSchema returns the schema for the type of field's value.
+func (d *schemaDocument) valueSchema(field *Field) (*schemaObject, error) {
+	var (
+		result = newSchemaObject()
+		err    error
+	)
+
+	switch {
+	case field.isJSONRaw:
+		// incompatible values can be anything
+		return result, nil
+	case field.IsStruct():
+		result, err = d.structRef(field.GetStruct())
+	case field.IsSlice() && len(anySlice(field.rawValue)) == 0:
+		// the elements of empty arrays can be anything
+		result.set("type", "array")
+	case field.IsSlice():
+		items, itemsErr := d.valueSchema(field.SliceElementField())
+
+		result.set("type", "array")
+
+		if len(items.keys) > 0 {
+			result.set("items", items)
+		}
+
+		err = itemsErr
+	case field.IsMap():
+		values, valuesErr := d.valueSchema(field.MapValueField())
+
+		result.set("type", "object").set("additionalProperties", values)
+
+		err = valuesErr
+	case field.IsNull():
+		result.set("type", "null")
+	default:
+		result = scalarSchema(field.rawValue)
+
+		if field.enum != nil {
+			result.set("enum", enumSchemaValues(field))
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if field.Nullable() {
+		return nullableSchema(result), nil
+	}
+
+	return result, nil
+}
+
+// scalarSchema returns the schema for the type of value, which is empty if it can be anything, e.g. null.
+func scalarSchema(value any) *schemaObject {
+	result := newSchemaObject()
+
+	switch val := value.(type) {
+	case int64, *big.Int:
+		result.set("type", "integer")
+	case float64, *big.Float:
+		result.set("type", "number")
+	case string:
+		result.set("type", "string")
+	case formattedString:
+		result.set("type", "string").set("format", string(val.format))
+	case bool:
+		result.set("type", "boolean")
+	}
+
+	return result
+}
+
+// enumSchemaValues returns the values of the enum declared for field, including null if the field is nullable.
+func enumSchemaValues(field *Field) []any {
+	results := []any{}
+
+	for _, value := range field.enum.values {
+		results = append(results, value)
+	}
+
+	if field.Nullable() {
+		results = append(results, nil)
+	}
+
+	return results
+}
+
+// nullableSchema returns a schema accepting null as well as the values accepted by schema.
+func nullableSchema(schema *schemaObject) *schemaObject {
+	if len(schema.keys) == 0 {
+		return schema
+	}
+
+	if typ, ok := schema.str("type"); ok {
+		return schema.set("type", []string{typ, "null"})
+	}
+
+	return newSchemaObject().set("anyOf", []any{schema, newSchemaObject().set("type", "null")})
+}
+
+// exampleValue returns value as it would appear in JSON if it's a scalar, or nil otherwise.
+func exampleValue(value any) any {
+	switch val := value.(type) {
+	case int64, float64, string, bool:
+		return val
+	case *big.Int:
+		return json.Number(val.String())
+	case *big.Float:
+		return json.Number(val.Text('g', -1))
+	case formattedString:
+		return val.value
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package jsonstruct_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestSchemaFormatter(t *testing.T) {
+	t.Parallel()
+
+	schemaHeader := `"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "Root", `
+
+	tests := []struct {
+		name       string
+		input      string
+		parserOpts *jsonstruct.ParserOptions
+		opts       *jsonstruct.FormatterOptions
+		expected   string
+	}{
+		{
+			name:  "types",
+			input: `{"a": 1, "b": 1.5, "c": "x", "d": true, "e": 123456789012345678901234567890, "f": null, "g": [1, 2.5]}`,
+			expected: `{` + schemaHeader + `"type": "object", "properties": {
+				"a": {"type": "integer"}, "b": {"type": "number"}, "c": {"type": "string"}, "d": {"type": "boolean"},
+				"e": {"type": "integer"}, "f": {"type": "null"}, "g": {"type": "array", "items": {"type": "number"}}
+			}, "required": ["a", "b", "c", "d", "e", "f", "g"]}`,
+		},
+		{
+			name:  "null_and_empty",
+			input: `{"a": null, "b": [null], "c": []}`,
+			expected: `{` + schemaHeader + `"type": "object", "properties": {
+				"a": {"type": "null"}, "b": {"type": "array", "items": {"type": "null"}}, "c": {"type": "array"}
+			}, "required": ["a", "b", "c"]}`,
+		},
+		{
+			name:  "top_level_array",
+			input: `[{"a": 1}, {"a": 2}]`,
+			expected: `{` + schemaHeader + `"type": "array", "items": {"$ref": "#/$defs/Root"}, "$defs": {
+				"Root": {"type": "object", "properties": {"a": {"type": "integer"}}, "required": ["a"]}
+			}}`,
+		},
+		{
+			name:     "top_level_scalar_array",
+			input:    `[1, 2]`,
+			expected: `{` + schemaHeader + `"type": "array", "items": {"type": "integer"}}`,
+		},
+		{
+			name:  "optional_and_nullable",
+			input: `[{"a": 1, "b": "x", "c": [1]}, {"a": null, "c": [null]}]`,
+			expected: `{` + schemaHeader + `"type": "array", "items": {"$ref": "#/$defs/Root"}, "$defs": {
+				"Root": {"type": "object", "properties": {
+					"a": {"type": ["integer", "null"]}, "b": {"type": "string"},
+					"c": {"type": "array", "items": {"type": ["integer", "null"]}}
+				}, "required": ["a", "c"]}
+			}}`,
+		},
+		{
+			name:       "formats",
+			input:      `{"a": "2024-01-02T03:04:05Z", "b": "2024-01-02"}`,
+			parserOpts: &jsonstruct.ParserOptions{InferTime: true},
+			expected: `{` + schemaHeader + `"type": "object", "properties": {
+				"a": {"type": "string", "format": "date-time"}, "b": {"type": "string", "format": "date"}
+			}, "required": ["a", "b"]}`,
+		},
+		{
+			name:  "enums",
+			input: `[{"a": "x"}, {"a": "y"}, {"a": null}, {"a": "x"}]`,
+			opts:  &jsonstruct.FormatterOptions{DetectEnums: true},
+			expected: `{` + schemaHeader + `"type": "array", "items": {"$ref": "#/$defs/Root"}, "$defs": {
+				"Root": {"type": "object", "properties": {
+					"a": {"type": ["string", "null"], "enum": ["x", "y", null]}
+				}, "required": ["a"]}
+			}}`,
+		},
+		{
+			name:  "defs",
+			input: `{"user": {"id": 1}, "users": [{"id": 2}], "other": [1, "x"]}`,
+			expected: `{` + schemaHeader + `"type": "object", "properties": {
+				"user": {"$ref": "#/$defs/User"}, "users": {"type": "array", "items": {"$ref": "#/$defs/Users"}},
+				"other": {"type": "array"}
+			}, "required": ["user", "users", "other"], "$defs": {
+				"User": {"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]},
+				"Users": {"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}
+			}}`,
+		},
+		{
+			name:  "dedupe",
+			input: `{"user": {"id": 1}, "users": [{"id": 2}]}`,
+			opts:  &jsonstruct.FormatterOptions{DedupeStructs: true},
+			expected: `{` + schemaHeader + `"type": "object", "properties": {
+				"user": {"$ref": "#/$defs/User"}, "users": {"type": "array", "items": {"$ref": "#/$defs/User"}}
+			}, "required": ["user", "users"], "$defs": {
+				"User": {"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}
+			}}`,
+		},
+		{
+			name:  "inline",
+			input: `{"user": {"id": 1}}`,
+			opts:  &jsonstruct.FormatterOptions{InlineStructs: true},
+			expected: `{` + schemaHeader + `"type": "object", "properties": {
+				"user": {"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}
+			}, "required": ["user"]}`,
+		},
+		{
+			name:       "maps",
+			input:      `{"1": {"a": 1}, "2": {"a": 2}}`,
+			parserOpts: &jsonstruct.ParserOptions{DetectMaps: true},
+			expected: `{` + schemaHeader + `"type": "object", "additionalProperties": {"$ref": "#/$defs/RootValue"},
+				"$defs": {
+					"RootValue": {"type": "object", "properties": {"a": {"type": "integer"}}, "required": ["a"]}
+				}}`,
+		},
+		{
+			name:       "examples_and_docs",
+			input:      "{\n// The A.\na: 1, b: [1]}",
+			parserOpts: &jsonstruct.ParserOptions{Lenient: true},
+			opts:       &jsonstruct.FormatterOptions{ValueComments: true},
+			expected: `{` + schemaHeader + `"type": "object", "properties": {
+				"a": {"type": "integer", "description": "The A.", "examples": [1]},
+				"b": {"type": "array", "items": {"type": "integer"}}
+			}, "required": ["a", "b"]}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parserOpts := test.parserOpts
+			if parserOpts == nil {
+				parserOpts = &jsonstruct.ParserOptions{}
+			}
+
+			parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(test.input), slog.Default(), parserOpts)
+			assert.Nil(t, err)
+
+			structs, err := parser.Start()
+			assert.Nil(t, err)
+
+			opts := test.opts
+			if opts == nil {
+				opts = &jsonstruct.FormatterOptions{}
+			}
+
+			formatter, err := jsonstruct.NewSchemaFormatter(opts)
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs[0].SetName("Root"))
+			assert.Nil(t, err)
+			assert.JSONEq(t, test.expected, output)
+		})
+	}
+}
+
+func TestSchemaFormatterOutput(t *testing.T) {
+	t.Parallel()
+
+	parser := jsonstruct.NewParser(strings.NewReader(`{"b": "<x>", "a": 1} {"c": false}`), slog.Default())
+
+	structs, err := parser.Start()
+	assert.Nil(t, err)
+
+	structs[0].SetName("First")
+	structs[1].SetName("Second")
+
+	var formatter jsonstruct.StructFormatter
+
+	formatter, err = jsonstruct.NewSchemaFormatter(&jsonstruct.FormatterOptions{SortFields: true, ValueComments: true})
+	assert.Nil(t, err)
+
+	output, err := formatter.FormatStructs(structs...)
+	assert.Nil(t, err)
+
+	// properties are sorted, and HTML characters aren't escaped
+	assert.Equal(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "anyOf": [
+    {
+      "$ref": "#/$defs/First"
+    },
+    {
+      "$ref": "#/$defs/Second"
+    }
+  ],
+  "$defs": {
+    "First": {
+      "type": "object",
+      "properties": {
+        "a": {
+          "type": "integer",
+          "examples": [
+            1
+          ]
+        },
+        "b": {
+          "type": "string",
+          "examples": [
+            "<x>"
+          ]
+        }
+      },
+      "required": [
+        "a",
+        "b"
+      ]
+    },
+    "Second": {
+      "type": "object",
+      "properties": {
+        "c": {
+          "type": "boolean",
+          "examples": [
+            false
+          ]
+        }
+      },
+      "required": [
+        "c"
+      ]
+    }
+  }
+}
+`, output)
+}
+
+func TestSchemaFormatterRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	input := `{"type": "object", "required": ["name"], "properties": {
+		"name": {"type": "string"}, "parent": {"$ref": "#"}, "children": {"type": "array", "items": {"$ref": "#"}}
+	}}`
+
+	structs, err := jsonstruct.NewSchemaParser(strings.NewReader(input), slog.Default()).Start()
+	assert.Nil(t, err)
+
+	formatter, err := jsonstruct.NewSchemaFormatter(&jsonstruct.FormatterOptions{})
+	assert.Nil(t, err)
+
+	output, err := formatter.FormatStructs(structs[0].SetName("Node"))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "Node", "type": "object",
+		"properties": {
+			"name": {"type": "string"}, "parent": {"$ref": "#"}, "children": {"type": "array", "items": {"$ref": "#"}}
+		}, "required": ["name"]}`, output)
+}
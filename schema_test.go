@@ -0,0 +1,252 @@
+package jsonstruct_test
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestSchemaParser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     *jsonstruct.FormatterOptions
+		expected string
+	}{
+		{
+			name: "required",
+			input: `{"type": "object", "required": ["id"], "properties": {
+				"id": {"type": "integer"}, "name": {"type": "string"}, "score": {"type": "number"}
+			}}`,
+			expected: "type Root struct {\n\tID    int64   `json:\"id\"`\n\tName  string  `json:\"name,omitempty\"`\n" +
+				"\tScore float64 `json:\"score,omitempty\"`\n}",
+		},
+		{
+			name: "enum_and_const",
+			input: `{"type": "object", "required": ["a", "b", "c"], "properties": {
+				"a": {"enum": ["x", "y"]}, "b": {"const": 1}, "c": {"enum": ["x", null]}
+			}}`,
+			expected: "type Root struct {\n\tA string  `json:\"a\"`\n\tB int64   `json:\"b\"`\n\tC *string `json:\"c\"`\n}",
+		},
+		{
+			name: "formats",
+			input: `{"type": "object", "required": ["a"], "properties": {
+				"a": {"type": "string", "format": "date-time"}, "b": {"type": "string", "format": "date"},
+				"c": {"type": "string", "format": "email"}
+			}}`,
+			expected: "type Root struct {\n\tA time.Time `json:\"a\"`\n\tB *Date     `json:\"b,omitempty\"`\n" +
+				"\tC string    `json:\"c,omitempty\"`\n}\n" + dateDeclaration,
+		},
+		{
+			name: "nullable",
+			input: `{"type": "object", "required": ["a", "b"], "properties": {
+				"a": {"type": ["integer", "null"]}, "b": {"anyOf": [{"type": "boolean"}, {"type": "null"}]}
+			}}`,
+			expected: "type Root struct {\n\tA *int64 `json:\"a\"`\n\tB *bool  `json:\"b\"`\n}",
+		},
+		{
+			name: "descriptions",
+			input: `{"type": "object", "required": ["a"], "properties": {
+				"a": {"type": "string", "description": "The A.\nSecond line."}
+			}}`,
+			expected: "type Root struct {\n\t// The A.\n\t// Second line.\n\tA string `json:\"a\"`\n}",
+		},
+		{
+			name: "examples",
+			input: `{"type": "object", "required": ["a", "b"], "properties": {
+				"a": {"type": "number", "examples": [2]}, "b": {"type": "string", "default": "x"}
+			}}`,
+			opts: &jsonstruct.FormatterOptions{ValueComments: true},
+			expected: "type Root struct {\n\tA float64 `json:\"a\"` // Example: 2.000\n" +
+				"\tB string  `json:\"b\"` // Example: \"x\"\n}",
+		},
+		{
+			name: "refs",
+			input: `{"type": "object", "required": ["billing", "shipping"], "properties": {
+				"billing": {"$ref": "#/$defs/address"}, "shipping": {"$ref": "#/$defs/address"}
+			}, "$defs": {"address": {"type": "object", "required": ["city"], "properties": {"city": {"type": "string"}}}}}`,
+			expected: "type Root struct {\n\tBilling  *Address `json:\"billing\"`\n\tShipping *Address `json:\"shipping\"`\n}" +
+				"\n\ntype Address struct {\n\tCity string `json:\"city\"`\n}",
+		},
+		{
+			name: "recursive_ref",
+			input: `{"type": "object", "required": ["root"], "properties": {"root": {"$ref": "#/$defs/node"}},
+				"$defs": {"node": {"type": "object", "required": ["children"], "properties": {
+					"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}
+				}}}}`,
+			expected: "type Root struct {\n\tRoot *Node `json:\"root\"`\n}\n\ntype Node struct {\n" +
+				"\tChildren []*Node `json:\"children\"`\n}",
+		},
+		{
+			name:     "recursive_root",
+			input:    `{"type": "object", "required": ["parent"], "properties": {"parent": {"$ref": "#"}}}`,
+			expected: "type Root struct {\n\tParent *Root `json:\"parent\"`\n}",
+		},
+		{
+			name: "one_of_objects",
+			input: `{"type": "object", "required": ["kind"], "properties": {"kind": {"type": "string"}},
+				"oneOf": [
+					{"properties": {"card": {"type": "string"}}, "required": ["card"]},
+					{"properties": {"iban": {"type": "string"}}, "required": ["iban"]}
+				]}`,
+			expected: "type Root struct {\n\tKind string `json:\"kind\"`\n\tCard string `json:\"card,omitempty\"`\n" +
+				"\tIban string `json:\"iban,omitempty\"`\n}",
+		},
+		{
+			name: "one_of_incompatible",
+			input: `{"type": "object", "required": ["a"], "properties": {
+				"a": {"oneOf": [{"type": "string"}, {"type": "integer"}]}
+			}}`,
+			expected: "type Root struct {\n\tA *json.RawMessage `json:\"a\"`\n}",
+		},
+		{
+			name: "all_of",
+			input: `{"allOf": [{"$ref": "#/$defs/base"}, {"required": ["id"], "properties": {"b": {"type": "boolean"}}}],
+				"$defs": {"base": {"type": "object", "properties": {"id": {"type": "integer"}}}}}`,
+			expected: "type Root struct {\n\tID int64 `json:\"id\"`\n\tB  bool  `json:\"b,omitempty\"`\n}",
+		},
+		{
+			name: "maps",
+			input: `{"type": "object", "required": ["a", "b"], "properties": {
+				"a": {"type": "object", "additionalProperties": {"type": "integer"}}, "b": {"type": "object"}
+			}}`,
+			expected: "type Root struct {\n\tA map[string]int64            `json:\"a\"`\n" +
+				"\tB map[string]*json.RawMessage `json:\"b\"`\n}",
+		},
+		{
+			name:     "root_array",
+			input:    `{"type": "array", "items": {"type": "string"}}`,
+			expected: "type Root []string",
+		},
+		{
+			name:     "root_string",
+			input:    `{"type": "string", "format": "date-time"}`,
+			expected: "type Root = time.Time",
+		},
+		{
+			name:     "true",
+			input:    `true`,
+			expected: "type Root = json.RawMessage",
+		},
+		{
+			name: "enums",
+			input: `{"type": "object", "required": ["status"], "properties": {
+				"status": {"type": "string", "enum": ["new", "paid"]}, "kind": {"$ref": "#/$defs/kind"},
+				"size": {"type": "integer", "enum": [1, 2]}
+			}, "$defs": {"kind": {"enum": ["a", "b", "c"]}}}`,
+			opts: &jsonstruct.FormatterOptions{DetectEnums: true},
+			expected: "type Root struct {\n\tStatus RootStatus `json:\"status\"`\n" +
+				"\tKind   RootKind   `json:\"kind,omitempty\"`\n\tSize   int64      `json:\"size,omitempty\"`\n}\n\n" +
+				"type RootStatus string\n\nconst (\n" +
+				"\tRootStatusNew  RootStatus = \"new\"\n\tRootStatusPaid RootStatus = \"paid\"\n)\n\n" +
+				"type RootKind string\n\nconst (\n\tRootKindA RootKind = \"a\"\n\tRootKindB RootKind = \"b\"\n" +
+				"\tRootKindC RootKind = \"c\"\n)",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			structs, err := jsonstruct.NewSchemaParser(strings.NewReader(test.input), slog.Default()).Start()
+			assert.Nil(t, err)
+
+			if !assert.Equal(t, 1, len(structs)) {
+				return
+			}
+
+			opts := test.opts
+			if opts == nil {
+				opts = &jsonstruct.FormatterOptions{}
+			}
+
+			formatter, err := jsonstruct.NewFormatter(opts)
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs[0].SetName("Root"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(output))
+		})
+	}
+}
+
+func TestSchemaParserErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unknown_type", `{"type": "thing"}`},
+		{"invalid_type", `{"type": 1}`},
+		{"missing_ref", `{"type": "object", "properties": {"a": {"$ref": "#/$defs/missing"}}}`},
+		{"invalid_schema", `{"type": "object", "properties": {"a": 1}}`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := jsonstruct.NewSchemaParser(strings.NewReader(test.input), slog.Default()).Start()
+			assert.True(t, errors.Is(err, jsonstruct.ErrInvalidSchema))
+		})
+	}
+}
+
+func TestSchemaParserRecursiveInline(t *testing.T) {
+	t.Parallel()
+
+	input := `{"type": "object", "properties": {"next": {"$ref": "#/$defs/node"}},
+		"$defs": {"node": {"type": "object", "properties": {"next": {"$ref": "#/$defs/node"}}}}}`
+
+	structs, err := jsonstruct.NewSchemaParser(strings.NewReader(input), slog.Default()).Start()
+	assert.Nil(t, err)
+
+	formatter, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{InlineStructs: true})
+	assert.Nil(t, err)
+
+	_, err = formatter.FormatStructs(structs...)
+	assert.True(t, errors.Is(err, jsonstruct.ErrRecursiveInline))
+}
+
+func TestSchemaParserTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		pattern  string
+		expected string
+	}{
+		{"title", `{"title": "Order", "type": "object", "properties": {"id": {"type": "integer"}}}`, "", "Order"},
+		{"title_scalar", `{"title": "order status", "type": "string"}`, "", "OrderStatus"},
+		{"no_title", `{"type": "object", "properties": {"id": {"type": "integer"}}}`, "", "S1"},
+		{"pattern", `{"title": "Order", "type": "object"}`, "Purchase", "Purchase"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			structs, err := jsonstruct.NewSchemaParser(strings.NewReader(test.input), slog.Default()).Start()
+			assert.Nil(t, err)
+
+			namer, err := jsonstruct.NewTypeNamer(test.pattern)
+			assert.Nil(t, err)
+
+			assert.Nil(t, namer.NameStructs("s.json", structs))
+			assert.Equal(t, test.expected, structs[0].Name())
+		})
+	}
+}
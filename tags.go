@@ -0,0 +1,233 @@
+package jsonstruct
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TagNaming decides how a struct tag derives the name of a field from its original key.
+type TagNaming string
+
+const (
+	// TagOriginal uses the original key unchanged. This is the default.
+	TagOriginal TagNaming = "original"
+	// TagSnake uses the key in snake_case, e.g. "userId" -> "user_id".
+	TagSnake TagNaming = "snake"
+	// TagCamel uses the key in camelCase, e.g. "user_id" -> "userId".
+	TagCamel TagNaming = "camel"
+)
+
+// ValidateTag is the key of go-playground/validator tags. Rather than a name, they hold "required" for fields that are
+// always present and never null, and the rules for the formats of string values typed as strings, e.g.
+// "uuid". They are left out of fields without any rules.
+const ValidateTag = "validate"
+
+// TagOptions defines a struct tag rendered for every field, in addition to the json tag (or yaml / toml tag for fields
+// parsed from those formats).
+type TagOptions struct {
+	// Key is the key of the tag, e.g. "bson", "db", or "mapstructure".
+	Key string
+
+	// Naming decides how the name in the tag is derived from the original key. Defaults to TagOriginal.
+	Naming TagNaming
+
+	// OmitEmpty adds ",omitempty" to the tag of optional fields.
+	OmitEmpty bool
+}
+
+// OK ensures that the options passed in are valid.
+func (t *TagOptions) OK() error {
+	if t.Key == "" || strings.IndexFunc(t.Key, func(r rune) bool {
+		return r <= ' ' || r == ':' || r == '"' || r == '`' || r == 0x7f
+	}) != -1 {
+		return fmt.Errorf("invalid tag key %q", t.Key)
+	}
+
+	switch t.Naming {
+	case "", TagOriginal, TagSnake, TagCamel:
+	default:
+		return fmt.Errorf("invalid naming %q for tag %q", t.Naming, t.Key)
+	}
+
+	return nil
+}
+
+// fieldTag returns the struct tag rendered for field: its own tag, followed by the ones configured in Tags. The field's
+// own tag is left out if its name matches its key and encoding/json would find it anyway, unless AlwaysTag is set or
+// the field holds numbers encoded as strings, which need the ",string" option. A tag in Tags with the key of the
+// field's own tag sets its naming and omitempty instead. A tag set by an Override replaces all of them.
+func (f *FormatterOptions) fieldTag(field *Field) string {
+	if field.tag != "" {
+		return "`" + field.tag + "`"
+	}
+
+	tags := []string{}
+	fieldTagKey := field.tagKey
+
+	if fieldTagKey == "" {
+		fieldTagKey = "json"
+	}
+
+	own := TagOptions{Key: fieldTagKey, OmitEmpty: true}
+	configured := false
+
+	for _, tag := range f.Tags {
+		if tag.Key == fieldTagKey {
+			own, configured = tag, true
+		}
+	}
+
+	name := tagName(field.OriginalName(), own.Naming)
+	omitEmpty := own.OmitEmpty && field.optional
+
+	switch {
+	case field.isStringNumber() && field.tagKey == "":
+		tags = append(tags, tagValue(fieldTagKey, name+",string", omitEmpty))
+	case f.AlwaysTag || configured || field.tagKey != "" || name != field.Name():
+		tags = append(tags, tagValue(fieldTagKey, name, omitEmpty))
+	}
+
+	for _, tag := range f.Tags {
+		switch tag.Key {
+		case fieldTagKey:
+			// already rendered as the field's own tag
+			continue
+		case ValidateTag:
+			if rules := validateRules(field); rules != "" {
+				tags = append(tags, tagValue(tag.Key, rules, false))
+			}
+
+			continue
+		}
+
+		tags = append(tags, tagValue(tag.Key, tagName(field.OriginalName(), tag.Naming), tag.OmitEmpty && field.optional))
+	}
+
+	if len(tags) == 0 {
+		return ""
+	}
+
+	return "`" + strings.Join(tags, " ") + "`"
+}
+
+// validateRules returns the value of the validate tag of field: "required" if it is always present and never null,
+// followed by the rule for the format of its values, if any. Optional fields are only validated when set.
+func validateRules(field *Field) string {
+	rules := []string{}
+	required := !field.optional && !field.Nullable() && !field.IsNull()
+
+	if required {
+		rules = append(rules, "required")
+	}
+
+	if rule := formatRule(field); rule != "" {
+		if !required {
+			rules = append(rules, "omitempty")
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return strings.Join(rules, ",")
+}
+
+// formatRule returns the validator rule for the format of the values of field, diving into slices and maps.
+func formatRule(field *Field) string {
+	if str, ok := field.rawValue.(formattedString); ok {
+		return str.validateRule(field.formatTypes)
+	}
+
+	element := field.SliceElementField()
+	if element == nil {
+		element = field.MapValueField()
+	}
+
+	if element != nil {
+		if rule := formatRule(element); rule != "" {
+			return "dive," + rule
+		}
+	}
+
+	return ""
+}
+
+// tagValue returns a single key:"value" pair of a struct tag.
+func tagValue(key, value string, omitEmpty bool) string {
+	if omitEmpty {
+		value += ",omitempty"
+	}
+
+	return fmt.Sprintf("%s:%q", key, value)
+}
+
+// tagName returns the name derived from key by naming.
+func tagName(key string, naming TagNaming) string {
+	words := splitWords(key)
+	if len(words) == 0 {
+		return key
+	}
+
+	switch naming {
+	case TagSnake:
+		for i, word := range words {
+			words[i] = strings.ToLower(word)
+		}
+
+		return strings.Join(words, "_")
+	case TagCamel:
+		for i, word := range words {
+			runes := []rune(strings.ToLower(word))
+			if i > 0 {
+				runes[0] = unicode.ToUpper(runes[0])
+			}
+
+			words[i] = string(runes)
+		}
+
+		return strings.Join(words, "")
+	}
+
+	return key
+}
+
+// splitWords splits key into words at separators and case changes, e.g. "userID" -> ["user", "ID"] and
+// "HTTPServer_name" -> ["HTTP", "Server", "name"]. Anything other than letters and digits is dropped.
+func splitWords(key string) []string {
+	words := []string{}
+	runes := []rune(key)
+	start := -1
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start != -1 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+
+			continue
+		}
+
+		if start == -1 {
+			start = i
+
+			continue
+		}
+
+		prev := runes[i-1]
+		// "aB", or the "S" of "HTTPServer"
+		lowerToUpper := unicode.IsUpper(r) && !unicode.IsUpper(prev)
+		acronymEnd := unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+		if lowerToUpper || acronymEnd {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	if start != -1 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
@@ -0,0 +1,141 @@
+package jsonstruct_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cneill/jsonstruct"
+)
+
+//nolint:funlen // it's a table-driven test :shrug:
+func TestFormatterTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		input      string
+		parserOpts *jsonstruct.ParserOptions
+		opts       *jsonstruct.FormatterOptions
+		expected   string
+	}{
+		{
+			name:     "default",
+			input:    `[{"Name": "a", "user_id": 1}, {"Name": "b"}]`,
+			opts:     &jsonstruct.FormatterOptions{},
+			expected: "type Root struct {\n\tName   string\n\tUserID int64 `json:\"user_id,omitempty\"`\n}",
+		},
+		{
+			name:     "always_tag",
+			input:    `{"Name": "a"}`,
+			opts:     &jsonstruct.FormatterOptions{AlwaysTag: true},
+			expected: "type Root struct {\n\tName string `json:\"Name\"`\n}",
+		},
+		{
+			name:  "naming",
+			input: `{"userID": 1, "HTTPServer": "x", "first-name": "y", "Name": "z"}`,
+			opts: &jsonstruct.FormatterOptions{Tags: []jsonstruct.TagOptions{
+				{Key: "bson", Naming: jsonstruct.TagSnake},
+				{Key: "mapstructure", Naming: jsonstruct.TagCamel},
+				{Key: "db"},
+			}},
+			expected: "type Root struct {\n" +
+				"\tUserID     int64  `json:\"userID\" bson:\"user_id\" mapstructure:\"userId\" db:\"userID\"`\n" +
+				"\tHTTPServer string `bson:\"http_server\" mapstructure:\"httpServer\" db:\"HTTPServer\"`\n" +
+				"\tFirstName  string `json:\"first-name\" bson:\"first_name\" mapstructure:\"firstName\" db:\"first-name\"`\n" +
+				"\tName       string `bson:\"name\" mapstructure:\"name\" db:\"Name\"`\n}",
+		},
+		{
+			name:  "omitempty_and_validate",
+			input: `[{"a": 1, "b": 2, "c": null}, {"a": 3, "c": 4}]`,
+			opts: &jsonstruct.FormatterOptions{Tags: []jsonstruct.TagOptions{
+				{Key: "bson", OmitEmpty: true},
+				{Key: "db", Naming: jsonstruct.TagSnake},
+				{Key: jsonstruct.ValidateTag},
+			}},
+			expected: "type Root struct {\n\tA int64  `json:\"a\" bson:\"a\" db:\"a\" validate:\"required\"`\n" +
+				"\tB int64  `json:\"b,omitempty\" bson:\"b,omitempty\" db:\"b\"`\n" +
+				"\tC *int64 `json:\"c\" bson:\"c\" db:\"c\"`\n}",
+		},
+		{
+			name:       "input_format_tag",
+			input:      "userName: x\n",
+			parserOpts: &jsonstruct.ParserOptions{InputFormat: jsonstruct.InputYAML},
+			opts: &jsonstruct.FormatterOptions{Tags: []jsonstruct.TagOptions{
+				{Key: "yaml", Naming: jsonstruct.TagSnake},
+				{Key: "json"},
+			}},
+			expected: "type Root struct {\n\tUserName string `yaml:\"user_name\" json:\"userName\"`\n}",
+		},
+		{
+			name:       "own_tag",
+			input:      `[{"userId": "1", "Name": "a"}, {"Name": "b"}]`,
+			parserOpts: &jsonstruct.ParserOptions{InferNumbers: true},
+			opts: &jsonstruct.FormatterOptions{Tags: []jsonstruct.TagOptions{
+				{Key: "json", Naming: jsonstruct.TagSnake},
+				{Key: "bson", OmitEmpty: true},
+			}},
+			expected: "type Root struct {\n\tUserId int64  `json:\"user_id,string\" bson:\"userId,omitempty\"`\n" +
+				"\tName   string `json:\"name\" bson:\"Name\"`\n}",
+		},
+		{
+			name:  "own_tag_omitempty",
+			input: `[{"a": 1}, {}]`,
+			opts: &jsonstruct.FormatterOptions{Tags: []jsonstruct.TagOptions{
+				{Key: "json", OmitEmpty: true},
+			}},
+			expected: "type Root struct {\n\tA int64 `json:\"a,omitempty\"`\n}",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			parserOpts := test.parserOpts
+			if parserOpts == nil {
+				parserOpts = &jsonstruct.ParserOptions{}
+			}
+
+			parser, err := jsonstruct.NewParserWithOptions(strings.NewReader(test.input), slog.Default(), parserOpts)
+			assert.Nil(t, err)
+
+			structs, err := parser.Start()
+			assert.Nil(t, err)
+
+			formatter, err := jsonstruct.NewFormatter(test.opts)
+			assert.Nil(t, err)
+
+			output, err := formatter.FormatStructs(structs[0].SetName("Root"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, strings.TrimSpace(output))
+		})
+	}
+}
+
+func TestFormatterTagsErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tags []jsonstruct.TagOptions
+	}{
+		{"empty_key", []jsonstruct.TagOptions{{Key: ""}}},
+		{"invalid_key", []jsonstruct.TagOptions{{Key: "a:b"}}},
+		{"invalid_naming", []jsonstruct.TagOptions{{Key: "bson", Naming: "upper"}}},
+		{"duplicate_key", []jsonstruct.TagOptions{{Key: "bson"}, {Key: "bson", Naming: jsonstruct.TagSnake}}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := jsonstruct.NewFormatter(&jsonstruct.FormatterOptions{Tags: test.tags})
+			assert.NotNil(t, err)
+		})
+	}
+}
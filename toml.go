@@ -0,0 +1,106 @@
+package jsonstruct
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// decodeTOML decodes a TOML document from input into the same values decodeYAMLValue returns, with tables decoded as
+// *schemaObject in the order their keys appear in the document. Dates and times are decoded as formattedStrings, since
+// TOML has a type for them.
+func decodeTOML(input io.Reader) (any, error) {
+	var doc map[string]any
+
+	meta, err := toml.NewDecoder(input).Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode TOML: %w", err)
+	}
+
+	order := map[string]int{}
+
+	// keys of arrays of tables show up once per table
+	for i, key := range meta.Keys() {
+		if _, ok := order[strings.Join(key, "\x00")]; !ok {
+			order[strings.Join(key, "\x00")] = i
+		}
+	}
+
+	return tomlValue(doc, nil, order), nil
+}
+
+// tomlValue converts value, found under the key path, with order giving the position of each key path in the document.
+func tomlValue(value any, path []string, order map[string]int) any {
+	switch val := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+
+		position := func(key string) int {
+			if i, ok := order[strings.Join(append(path, key), "\x00")]; ok {
+				return i
+			}
+
+			return len(order)
+		}
+
+		sort.SliceStable(keys, func(i, j int) bool {
+			if position(keys[i]) != position(keys[j]) {
+				return position(keys[i]) < position(keys[j])
+			}
+
+			return keys[i] < keys[j]
+		})
+
+		obj := newSchemaObject()
+
+		for _, key := range keys {
+			obj.set(key, tomlValue(val[key], append(append([]string{}, path...), key), order))
+		}
+
+		return obj
+	case []map[string]any:
+		results := make([]any, 0, len(val))
+
+		for _, table := range val {
+			results = append(results, tomlValue(table, path, order))
+		}
+
+		return results
+	case []any:
+		results := make([]any, 0, len(val))
+
+		for _, item := range val {
+			results = append(results, tomlValue(item, path, order))
+		}
+
+		return results
+	case int64:
+		return json.Number(strconv.FormatInt(val, 10))
+	case float64:
+		if math.IsInf(val, 0) || math.IsNaN(val) {
+			return val
+		}
+
+		return floatNumber(val)
+	case time.Time:
+		// local dates are decoded in a zone named after them
+		if val.Location().String() == "date-local" {
+			return formattedString{value: val.Format(time.DateOnly), format: FormatDate}
+		}
+
+		return formattedString{value: val.Format(time.RFC3339Nano), format: FormatDateTime}
+	}
+
+	// strings and bools
+	return value
+}
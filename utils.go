@@ -84,10 +84,12 @@ func simpleAnyToString(input any) string {
 		return fmt.Sprintf("%t", val)
 	case float64, *big.Float:
 		return fmt.Sprintf("%.3f", val)
-	case int64:
+	case int64, *big.Int:
 		return fmt.Sprintf("%d", val)
 	case string:
 		return fmt.Sprintf("\"%s\"", val)
+	case formattedString:
+		return fmt.Sprintf("\"%s\"", val.value)
 	}
 
 	return ""
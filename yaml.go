@@ -0,0 +1,166 @@
+package jsonstruct
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeDocument decodes a JSON or YAML document from input the same way decodeSchemaValue does, with objects decoded
+// as *schemaObject and numbers as json.Number. Documents starting with "{" or "[" are decoded as JSON, anything else as
+// YAML.
+func decodeDocument(input io.Reader) (any, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber()
+
+		return decodeSchemaValue(decoder)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to decode YAML: %w", err)
+	}
+
+	return decodeYAMLValue(&node)
+}
+
+// decodeYAMLValue converts node to the values decodeSchemaValue would return for the equivalent JSON. Timestamps and
+// other scalars without a JSON equivalent are kept as strings.
+func decodeYAMLValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+
+		return decodeYAMLValue(node.Content[0])
+	case yaml.AliasNode:
+		return decodeYAMLValue(node.Alias)
+	case yaml.MappingNode:
+		obj := newSchemaObject()
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: unsupported non-scalar key", node.Content[i].Line)
+			}
+
+			key := node.Content[i].Value
+
+			value, err := decodeYAMLValue(node.Content[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %q: %w", key, err)
+			}
+
+			obj.set(key, value)
+		}
+
+		return obj, nil
+	case yaml.SequenceNode:
+		list := []any{}
+
+		for _, item := range node.Content {
+			value, err := decodeYAMLValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode element %d: %w", len(list), err)
+			}
+
+			list = append(list, value)
+		}
+
+		return list, nil
+	case yaml.ScalarNode:
+		return decodeYAMLScalar(node)
+	}
+
+	return nil, fmt.Errorf("line %d: unexpected YAML node kind %d", node.Line, node.Kind)
+}
+
+// decodeYAMLScalar converts a scalar node according to its resolved tag.
+func decodeYAMLScalar(node *yaml.Node) (any, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var val bool
+		if err := node.Decode(&val); err != nil {
+			return nil, fmt.Errorf("line %d: invalid bool %q: %w", node.Line, node.Value, err)
+		}
+
+		return val, nil
+	case "!!int":
+		// e.g. 0x1F or 1_000, which aren't valid JSON numbers
+		val, ok := (&big.Int{}).SetString(strings.ReplaceAll(node.Value, "_", ""), 0)
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid integer %q", node.Line, node.Value)
+		}
+
+		return json.Number(val.String()), nil
+	case "!!float":
+		if json.Valid([]byte(node.Value)) {
+			return json.Number(node.Value), nil
+		}
+
+		var val float64
+		if err := node.Decode(&val); err != nil {
+			return nil, fmt.Errorf("line %d: invalid number %q: %w", node.Line, node.Value, err)
+		}
+
+		// .inf and .nan don't have a JSON equivalent
+		if math.IsInf(val, 0) || math.IsNaN(val) {
+			return node.Value, nil
+		}
+
+		// e.g. .5 or +1.5
+		return floatNumber(val), nil
+	}
+
+	return node.Value, nil
+}
+
+// floatNumber returns val as a json.Number that is still parsed as a float, even if it's a whole number.
+func floatNumber(val float64) json.Number {
+	number := strconv.FormatFloat(val, 'g', -1, 64)
+	if !strings.ContainsAny(number, ".eE") {
+		number += ".0"
+	}
+
+	return json.Number(number)
+}
+
+// decodeYAMLDocuments decodes every document in a YAML stream, e.g. the ones separated by "---".
+func decodeYAMLDocuments(input io.Reader) ([]any, error) {
+	decoder := yaml.NewDecoder(input)
+	results := []any{}
+
+	for {
+		var node yaml.Node
+
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode YAML document %d: %w", len(results)+1, err)
+		}
+
+		value, err := decodeYAMLValue(&node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode YAML document %d: %w", len(results)+1, err)
+		}
+
+		results = append(results, value)
+	}
+}